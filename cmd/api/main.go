@@ -23,12 +23,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	"github.com/mohan2020coder/mSpace/internal/api"
+	"github.com/mohan2020coder/mSpace/internal/auth"
 	"github.com/mohan2020coder/mSpace/internal/config"
 	"github.com/mohan2020coder/mSpace/internal/db"
+	"github.com/mohan2020coder/mSpace/internal/embed"
+	"github.com/mohan2020coder/mSpace/internal/extract"
+	"github.com/mohan2020coder/mSpace/internal/jobs"
 	"github.com/mohan2020coder/mSpace/internal/logger"
 	"github.com/mohan2020coder/mSpace/internal/models"
 	"github.com/mohan2020coder/mSpace/internal/search"
@@ -51,6 +56,10 @@ func main() {
 	zsugar := zl.Sugar()
 	zsugar.Infof("starting with config: %+v", cfg.Server)
 
+	if err := auth.ValidateSecret(cfg.Auth.JWTSecret); err != nil {
+		zl.Fatal("set MSPACE_JWT_SECRET to a random secret of at least 32 bytes", zap.Error(err))
+	}
+
 	// Init DB
 	gdb := db.Init(cfg.Database.DSN)
 
@@ -60,26 +69,77 @@ func main() {
 		&models.Collection{},
 		&models.Item{},
 		&models.Metadata{},
+		&models.User{},
+		&models.Group{},
+		&models.ResourcePolicy{},
+		&models.WorkflowStep{},
+		&models.ItemEvent{},
+		&models.Bitstream{},
+		&models.BitstreamPage{},
+		&models.ItemChunk{},
+		&models.UploadSession{},
+		&models.UploadPart{},
+		&models.Job{},
 	); err != nil {
 		zl.Fatal("AutoMigrate failed", zap.Error(err))
 	}
 
-	// Init MinIO
-	minioClient := storage.NewMinio(cfg.Storage.Endpoint, cfg.Storage.AccessKey, cfg.Storage.SecretKey, cfg.Storage.Bucket, cfg.Storage.SSL)
+	// Init storage backend
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		zl.Fatal("failed to init storage", zap.String("provider", cfg.Storage.Provider), zap.Error(err))
+	}
 
+	tika := extract.NewTika(cfg.Tika, zl)
+	embedder, err := embed.New(cfg.Embedding)
+	if err != nil {
+		zl.Fatal("failed to init embedding provider", zap.Error(err))
+	}
 	app := &api.App{
-		Cfg:    cfg,
-		DB:     gdb,
-		Minio:  minioClient,
-		Logger: zl,
+		Cfg:        cfg,
+		DB:         gdb,
+		Storage:    store,
+		Jobs:       jobs.NewQueue(gdb, zl, cfg.Jobs),
+		Extractors: extract.Default(tika, zl),
+		Tika:       tika,
+		Embedder:   embedder,
+		Logger:     zl,
 	}
 
-	index, err := search.NewIndex("./bleve_index")
+	if err := app.EnsureAdminUser(); err != nil {
+		zl.Fatal("failed to create admin user", zap.Error(err))
+	}
+	if err := app.MigrateBitstreams(); err != nil {
+		zl.Fatal("bitstream migration failed", zap.Error(err))
+	}
+	if err := app.MigrateLegacyFileURLs(context.Background()); err != nil {
+		zl.Error("legacy file url migration failed", zap.Error(err))
+	}
+
+	index, err := search.NewIndex(cfg.Search.IndexPath)
 	if err != nil {
 		zl.Fatal("failed to init search index", zap.Error(err))
 	}
-	
-	r := api.SetupRouter(app,index)
+	if index.Rebuilt {
+		zl.Warn("search index mapping changed, reindexing all items",
+			zap.String("from", index.PreviousVersion), zap.String("to", search.MappingVersion))
+		if _, err := app.Jobs.Enqueue(gdb, api.JobReindexAll, nil, struct{}{}); err != nil {
+			zl.Error("failed to queue reindex", zap.Error(err))
+		}
+	}
+
+	app.RegisterJobs(index)
+	go app.Jobs.Run(context.Background())
+	go app.RunEmbargoLifter(context.Background())
+	go app.RunUploadCleanup(context.Background())
+	if cfg.Search.Reconcile {
+		go app.RunIndexReconciler(context.Background(), index)
+	}
+	if cfg.Fixity.Enabled {
+		go app.RunFixityChecker(context.Background())
+	}
+
+	r := api.SetupRouter(app, index)
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	zsugar.Infof("API listening on %s", addr)
 	if err := r.Run(addr); err != nil {
@@ -0,0 +1,118 @@
+// internal/api/audit.go
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+)
+
+// Audit actions
+const (
+	EventCreate         = "CREATE"
+	EventUpdate         = "UPDATE"
+	EventUpload         = "UPLOAD"
+	EventRestore        = "RESTORE"
+	EventRemoveFile     = "REMOVE_FILE"
+	EventSubmit         = "SUBMIT"
+	EventStartReview    = "START_REVIEW"
+	EventApprove        = "APPROVE"
+	EventPublish        = "PUBLISH"
+	EventRequestChanges = "REQUEST_CHANGES"
+	EventReject         = "REJECT"
+	EventWithdraw       = "WITHDRAW"
+	EventEmbargoLifted  = "EMBARGO_LIFTED"
+	EventDelete         = "DELETE"
+)
+
+// auditedFields are the item fields whose changes end up in ItemEvent.Changes.
+// FullText and LegalJSON are left out on purpose, they are derived and large.
+var auditedFields = []string{
+	"Title", "Author", "Abstract", "Status", "FileURL", "Version", "CollectionID",
+	"Visibility", "EmbargoUntil", "SubmitterID", "WorkflowStep", "ReviewComment",
+}
+
+type fieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// diffItems returns the audited fields that differ, keyed by their JSON name
+func diffItems(before, after *models.Item) map[string]fieldChange {
+	if before == nil {
+		before = &models.Item{}
+	}
+	changes := map[string]fieldChange{}
+	bv, av := reflect.ValueOf(*before), reflect.ValueOf(*after)
+	t := bv.Type()
+	for _, name := range auditedFields {
+		f, _ := t.FieldByName(name)
+		key, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = name
+		}
+		oldV, newV := bv.FieldByName(name).Interface(), av.FieldByName(name).Interface()
+		if reflect.DeepEqual(oldV, newV) {
+			continue
+		}
+		changes[key] = fieldChange{Old: oldV, New: newV}
+	}
+	return changes
+}
+
+// recordItemEvent appends an audit entry; before is nil for newly created items.
+// extra carries changes that are not item columns (e.g. metadata keys).
+func recordItemEvent(tx *gorm.DB, actor *models.User, action string, before, after *models.Item, comment string, extra map[string]fieldChange) error {
+	changes := diffItems(before, after)
+	for k, v := range extra {
+		changes[k] = v
+	}
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	event := models.ItemEvent{
+		ItemID:    after.ID,
+		Action:    action,
+		NewStatus: after.Status,
+		Changes:   models.JSONText(b),
+		Comment:   comment,
+	}
+	if before != nil {
+		event.OldStatus = before.Status
+	}
+	if actor != nil {
+		event.ActorID = actor.ID
+		event.Actor = actor.Username
+	} else {
+		event.Actor = "system"
+	}
+	return tx.Create(&event).Error
+}
+
+func itemHistoryHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadVisibleItem(app, c)
+		if !ok {
+			return
+		}
+		var events []models.ItemEvent
+		if err := app.DB.Where("item_id = ?", item.ID).Order("id").Find(&events).Error; err != nil {
+			app.Logger.Error("db list item events failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load history"})
+			return
+		}
+		c.JSON(http.StatusOK, events)
+	}
+}
@@ -0,0 +1,54 @@
+// internal/api/audit_test.go
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+)
+
+func TestDiffItemsEmbargo(t *testing.T) {
+	until := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := &models.Item{Title: "Appeal", Visibility: models.VisibilityPublic}
+	after := &models.Item{Title: "Appeal", Visibility: models.VisibilityPrivate, EmbargoUntil: &until}
+
+	changes := diffItems(before, after)
+	if len(changes) != 2 {
+		t.Fatalf("got changes %v, want visibility and embargo_until", changes)
+	}
+	c, ok := changes["embargo_until"]
+	if !ok {
+		t.Fatalf("embargo change missing or misnamed: %v", changes)
+	}
+	if old, _ := c.Old.(*time.Time); old != nil {
+		t.Errorf("old embargo %v, want nil", c.Old)
+	}
+	if got, _ := c.New.(*time.Time); got == nil || !got.Equal(until) {
+		t.Errorf("new embargo %v, want %v", c.New, until)
+	}
+	if c := changes["visibility"]; c.Old != models.VisibilityPublic || c.New != models.VisibilityPrivate {
+		t.Errorf("visibility change %+v", c)
+	}
+
+	// An equal date held in a different pointer is no change
+	same := until
+	after2 := *after
+	after2.EmbargoUntil = &same
+	if changes := diffItems(after, &after2); len(changes) != 0 {
+		t.Errorf("unchanged embargo reported: %v", changes)
+	}
+}
+
+func TestDiffItemsCreate(t *testing.T) {
+	changes := diffItems(nil, &models.Item{Title: "Appeal", Status: "DRAFT"})
+	if changes["title"].New != "Appeal" || changes["status"].New != "DRAFT" {
+		t.Errorf("changes %v", changes)
+	}
+	for key := range changes {
+		if strings.Contains(key, ",") {
+			t.Errorf("key %q carries tag options", key)
+		}
+	}
+}
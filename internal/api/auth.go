@@ -0,0 +1,156 @@
+// internal/api/auth.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/auth"
+	"github.com/mohan2020coder/mSpace/internal/models"
+)
+
+const claimsKey = "auth.claims"
+
+// ---------------- Middleware ----------------
+
+// authRequired rejects requests without a valid bearer token
+func authRequired(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenStr, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(tokenStr) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		claims, err := auth.ParseToken(app.Cfg.Auth.JWTSecret, strings.TrimSpace(tokenStr))
+		if err != nil {
+			msg := "invalid token"
+			if errors.Is(err, auth.ErrTokenExpired) {
+				msg = "token expired"
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": msg})
+			return
+		}
+
+		c.Set(claimsKey, claims)
+		c.Next()
+	}
+}
+
+// currentClaims returns the claims set by authRequired, or nil
+func currentClaims(c *gin.Context) *auth.Claims {
+	v, ok := c.Get(claimsKey)
+	if !ok {
+		return nil
+	}
+	claims, _ := v.(*auth.Claims)
+	return claims
+}
+
+// ---------------- Auth ----------------
+
+type loginReq struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func loginHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req loginReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var user models.User
+		if err := app.DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return
+		}
+		if !auth.CheckPassword(user.PasswordHash, req.Password) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return
+		}
+
+		respondWithToken(app, c, user.ID, user.Username)
+	}
+}
+
+// refreshHandler exchanges a still valid token for a new one with a full expiry window
+func refreshHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentClaims(c)
+
+		var user models.User
+		if err := app.DB.First(&user, claims.UserID).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user no longer exists"})
+			return
+		}
+
+		respondWithToken(app, c, user.ID, user.Username)
+	}
+}
+
+func respondWithToken(app *App, c *gin.Context, userID uint, username string) {
+	token, expiresAt, err := auth.IssueToken(app.Cfg.Auth.JWTSecret, userID, username, app.Cfg.Auth.TokenExpiry)
+	if err != nil {
+		app.Logger.Error("token signing failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_at": expiresAt,
+	})
+}
+
+// weakAdminPasswords are defaults that must not protect a site admin
+var weakAdminPasswords = map[string]bool{"admin": true, "password": true, "changeme": true, "secret": true}
+
+// EnsureAdminUser creates the bootstrap account from auth.admin_username if it does not
+// exist yet. Creating it requires a password from MSPACE_ADMIN_PASSWORD that is not a
+// well-known default.
+func (app *App) EnsureAdminUser() error {
+	username := app.Cfg.Auth.AdminUsername
+	if username == "" {
+		return nil
+	}
+
+	var user models.User
+	err := app.DB.Where("username = ?", username).First(&user).Error
+	if err == nil {
+		if !user.IsAdmin {
+			return app.DB.Model(&user).Update("is_admin", true).Error
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	password := app.Cfg.Auth.AdminPassword
+	if password == "" {
+		return fmt.Errorf("admin user %q does not exist; set MSPACE_ADMIN_PASSWORD to create it", username)
+	}
+	if weakAdminPasswords[strings.ToLower(password)] || strings.EqualFold(password, username) {
+		return fmt.Errorf("refusing to create admin user %q with a default password", username)
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user = models.User{Username: username, PasswordHash: hash, IsAdmin: true}
+	if err := app.DB.Create(&user).Error; err != nil {
+		return err
+	}
+	app.Logger.Info("created bootstrap admin user", zap.String("username", username))
+	return nil
+}
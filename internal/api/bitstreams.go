@@ -0,0 +1,548 @@
+// internal/api/bitstreams.go
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/storage"
+)
+
+// downloadURLExpiry bounds the presigned links handed out by ?redirect=true downloads
+const downloadURLExpiry = 15 * time.Minute
+
+// primarySequence is the ORIGINAL file that Item.Version and Item.FileURL refer to
+const primarySequence = 1
+
+func validBundle(bundle string) bool {
+	switch bundle {
+	case models.BundleOriginal, models.BundleText, models.BundleThumbnail, models.BundleLicense:
+		return true
+	}
+	return false
+}
+
+// itemFileURL is the stable API path stored in Item.FileURL
+func itemFileURL(itemID uint) string {
+	return fmt.Sprintf("/api/items/%d/file", itemID)
+}
+
+// bundleFileURL is the stable API path of a file inside a bundle
+func bundleFileURL(bs *models.Bitstream) string {
+	return fmt.Sprintf("/api/items/%d/bundles/%s/%d/file", bs.ItemID, bs.Bundle, bs.Sequence)
+}
+
+func fileLabel(bs *models.Bitstream) string {
+	return fmt.Sprintf("%s/%d: %s", bs.Bundle, bs.Sequence, bs.FileName)
+}
+
+// bitstreamObjectName builds the storage key, e.g. item-12/original/1-v3-1700000000.pdf
+func bitstreamObjectName(itemID uint, bundle string, sequence, version int, fileName string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	return fmt.Sprintf("item-%d/%s/%d-v%d-%d%s", itemID, strings.ToLower(bundle), sequence, version, time.Now().Unix(), ext)
+}
+
+// nextBitstreamVersion returns one past the highest version ever stored for the file
+func (app *App) nextBitstreamVersion(itemID uint, bundle string, sequence int) (int, error) {
+	var max int
+	err := app.DB.Unscoped().Model(&models.Bitstream{}).
+		Where("item_id = ? AND bundle = ? AND sequence = ?", itemID, bundle, sequence).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&max).Error
+	return max + 1, err
+}
+
+// targetSequence picks the file slot an upload goes to: an explicit sequence,
+// the next free one when appendNew is set, or the first file of the bundle otherwise
+func (app *App) targetSequence(itemID uint, bundle, sequenceParam string, appendNew bool) (int, error) {
+	if sequenceParam != "" {
+		seq, err := strconv.Atoi(sequenceParam)
+		if err != nil || seq < 1 {
+			return 0, errors.New("sequence must be a positive integer")
+		}
+		return seq, nil
+	}
+	if !appendNew {
+		return primarySequence, nil
+	}
+	var max int
+	err := app.DB.Unscoped().Model(&models.Bitstream{}).
+		Where("item_id = ? AND bundle = ?", itemID, bundle).
+		Select("COALESCE(MAX(sequence), 0)").
+		Scan(&max).Error
+	return max + 1, err
+}
+
+// putBitstream stores the content as the next version of the file and returns the
+// unsaved record; saveBitstream makes it current
+func (app *App) putBitstream(ctx context.Context, itemID uint, bundle string, sequence int, fileName, mimeType string, r io.Reader, size int64, uploaderID uint) (*models.Bitstream, error) {
+	version, err := app.nextBitstreamVersion(itemID, bundle, sequence)
+	if err != nil {
+		return nil, err
+	}
+	objectName := bitstreamObjectName(itemID, bundle, sequence, version, fileName)
+
+	digest := storage.NewDigestReader(r)
+	if err := app.Storage.Put(ctx, objectName, digest, size, mimeType); err != nil {
+		return nil, err
+	}
+	sums := digest.Digests()
+
+	return &models.Bitstream{
+		ItemID:       itemID,
+		Bundle:       bundle,
+		Sequence:     sequence,
+		Version:      version,
+		ObjectName:   objectName,
+		FileName:     fileName,
+		Size:         size,
+		Checksum:     sums.SHA256,
+		ChecksumMD5:  sums.MD5,
+		MimeType:     mimeType,
+		UploaderID:   uploaderID,
+		LegalJSON:    "{}",
+		FixityStatus: models.FixityUnchecked,
+	}, nil
+}
+
+// saveBitstream inserts bs as the current version of its file
+func saveBitstream(tx *gorm.DB, bs *models.Bitstream) error {
+	err := tx.Model(&models.Bitstream{}).
+		Where("item_id = ? AND bundle = ? AND sequence = ? AND current", bs.ItemID, bs.Bundle, bs.Sequence).
+		Update("current", false).Error
+	if err != nil {
+		return err
+	}
+	bs.Current = true
+	return tx.Create(bs).Error
+}
+
+// refreshItemFiles derives the item's file fields from its current ORIGINAL files:
+// Version/FileURL/LegalJSON follow the primary file, FullText covers all of them
+func refreshItemFiles(tx *gorm.DB, item *models.Item) error {
+	var originals []models.Bitstream
+	err := tx.Where("item_id = ? AND bundle = ? AND current", item.ID, models.BundleOriginal).
+		Order("sequence").Find(&originals).Error
+	if err != nil {
+		return err
+	}
+
+	item.Version, item.FileURL, item.LegalJSON = 0, "", "{}"
+	var texts []string
+	for _, bs := range originals {
+		if bs.Sequence == primarySequence {
+			item.Version = bs.Version
+			item.FileURL = itemFileURL(item.ID)
+			if bs.LegalJSON != "" {
+				item.LegalJSON = bs.LegalJSON
+			}
+		}
+		if strings.TrimSpace(bs.FullText) != "" {
+			texts = append(texts, bs.FullText)
+		}
+	}
+	item.FullText = strings.Join(texts, "\n")
+	return nil
+}
+
+// detectMimeType prefers the client supplied type and falls back to the file extension
+func detectMimeType(fh *multipart.FileHeader) string {
+	if ct := fh.Header.Get("Content-Type"); ct != "" && ct != "application/octet-stream" {
+		return ct
+	}
+	if ct := mime.TypeByExtension(filepath.Ext(fh.Filename)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// fileParams reads the file a request addresses from the :bundle/:seq route params,
+// or the bundle and seq query params on routes without them; the primary file is the default
+func fileParams(c *gin.Context) (string, int, bool) {
+	bundle, seqParam := c.Param("bundle"), c.Param("seq")
+	if bundle == "" {
+		bundle, seqParam = c.DefaultQuery("bundle", models.BundleOriginal), c.DefaultQuery("seq", strconv.Itoa(primarySequence))
+	}
+	bundle = strings.ToUpper(bundle)
+	seq, err := strconv.Atoi(seqParam)
+	if !validBundle(bundle) || err != nil || seq < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bundle or sequence"})
+		return "", 0, false
+	}
+	return bundle, seq, true
+}
+
+// loadBitstreamVersion resolves the :version param against the addressed file
+func loadBitstreamVersion(app *App, c *gin.Context, item *models.Item) (*models.Bitstream, bool) {
+	bundle, seq, ok := fileParams(c)
+	if !ok {
+		return nil, false
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+		return nil, false
+	}
+	var bs models.Bitstream
+	err = app.DB.Where("item_id = ? AND bundle = ? AND sequence = ? AND version = ?",
+		item.ID, bundle, seq, version).First(&bs).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "version not found"})
+		return nil, false
+	}
+	return &bs, true
+}
+
+// loadBundleFile resolves the :bundle/:seq params to the current version of that file
+func loadBundleFile(app *App, c *gin.Context, item *models.Item) (*models.Bitstream, bool) {
+	bundle, seq, ok := fileParams(c)
+	if !ok {
+		return nil, false
+	}
+	var bs models.Bitstream
+	err := app.DB.Where("item_id = ? AND bundle = ? AND sequence = ? AND current", item.ID, bundle, seq).First(&bs).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+		return nil, false
+	}
+	return &bs, true
+}
+
+// serveBitstream streams the object with Range support, or redirects to a
+// fresh short-lived presigned URL when ?redirect=true is given
+func serveBitstream(app *App, c *gin.Context, bs *models.Bitstream) {
+	ctx := c.Request.Context()
+
+	// Providers without presigning (fs, memory) fall back to streaming
+	if redirect, _ := strconv.ParseBool(c.Query("redirect")); redirect {
+		url, err := app.Storage.Presign(ctx, bs.ObjectName, downloadURLExpiry)
+		switch {
+		case err == nil:
+			c.Redirect(http.StatusFound, url)
+			return
+		case !errors.Is(err, storage.ErrPresignUnsupported):
+			app.Logger.Error("presign failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get file url"})
+			return
+		}
+	}
+
+	obj, info, err := app.Storage.Get(ctx, bs.ObjectName)
+	if err != nil {
+		app.Logger.Error("storage open failed", zap.String("object", bs.ObjectName), zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "file not available"})
+		return
+	}
+	defer obj.Close()
+
+	c.Header("Content-Type", bs.MimeType)
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": bs.FileName}))
+	if bs.Checksum != "" {
+		c.Header("ETag", `"`+bs.Checksum+`"`)
+	}
+	http.ServeContent(c.Writer, c.Request, bs.FileName, info.LastModified, obj)
+}
+
+// ---------------- Files ----------------
+
+// currentFileHandler serves the current version of the item's primary file
+func currentFileHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadVisibleItem(app, c)
+		if !ok {
+			return
+		}
+		var bs models.Bitstream
+		err := app.DB.Where("item_id = ? AND bundle = ? AND sequence = ? AND current",
+			item.ID, models.BundleOriginal, primarySequence).First(&bs).Error
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item has no file"})
+			return
+		}
+		serveBitstream(app, c, &bs)
+	}
+}
+
+// listBundlesHandler returns the current files of the item grouped by bundle
+func listBundlesHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadVisibleItem(app, c)
+		if !ok {
+			return
+		}
+		var files []models.Bitstream
+		if err := app.DB.Where("item_id = ? AND current", item.ID).Order("bundle, sequence").Find(&files).Error; err != nil {
+			app.Logger.Error("db list bundles failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list bundles"})
+			return
+		}
+		bundles := map[string][]models.Bitstream{}
+		for _, f := range files {
+			bundles[f.Bundle] = append(bundles[f.Bundle], f)
+		}
+		c.JSON(http.StatusOK, bundles)
+	}
+}
+
+func bundleFileHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadVisibleItem(app, c)
+		if !ok {
+			return
+		}
+		bs, ok := loadBundleFile(app, c, item)
+		if !ok {
+			return
+		}
+		serveBitstream(app, c, bs)
+	}
+}
+
+// removeBundleFileHandler detaches a file (all its versions) from the item.
+// Stored objects are kept; removing an ORIGINAL file also removes its derived text.
+func removeBundleFileHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		if !authorizeItemFiles(app, c, item) {
+			return
+		}
+		bs, ok := loadBundleFile(app, c, item)
+		if !ok {
+			return
+		}
+
+		bundles := []string{bs.Bundle}
+		if bs.Bundle == models.BundleOriginal {
+			bundles = append(bundles, models.BundleText)
+		}
+
+		before := *item
+		actor, _ := currentUser(app, c)
+		err := app.DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Where("item_id = ? AND bundle IN ? AND sequence = ?", item.ID, bundles, bs.Sequence).
+				Delete(&models.Bitstream{}).Error
+			if err != nil {
+				return err
+			}
+			if err := refreshItemFiles(tx, item); err != nil {
+				return err
+			}
+			if err := tx.Save(item).Error; err != nil {
+				return err
+			}
+			if err := recordItemEvent(tx, actor, EventRemoveFile, &before, item, fileLabel(bs), nil); err != nil {
+				return err
+			}
+			if err := app.queueIndex(tx, item.ID); err != nil {
+				return err
+			}
+			return app.queueEmbed(tx, item.ID)
+		})
+		if err != nil {
+			app.Logger.Error("db remove file failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove file"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "file removed"})
+	}
+}
+
+// ---------------- Versions ----------------
+
+func listVersionsHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadVisibleItem(app, c)
+		if !ok {
+			return
+		}
+		bundle, seq, ok := fileParams(c)
+		if !ok {
+			return
+		}
+		var versions []models.Bitstream
+		err := app.DB.Where("item_id = ? AND bundle = ? AND sequence = ?", item.ID, bundle, seq).
+			Order("version desc").Find(&versions).Error
+		if err != nil {
+			app.Logger.Error("db list versions failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list versions"})
+			return
+		}
+		c.JSON(http.StatusOK, versions)
+	}
+}
+
+// versionFileHandler serves a specific version of a file, the primary one by default
+func versionFileHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadVisibleItem(app, c)
+		if !ok {
+			return
+		}
+		bs, ok := loadBitstreamVersion(app, c, item)
+		if !ok {
+			return
+		}
+		serveBitstream(app, c, bs)
+	}
+}
+
+// restoreVersionHandler makes an older version of a file, the primary one by default, current again
+func restoreVersionHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		if !authorizeItemFiles(app, c, item) {
+			return
+		}
+		bs, ok := loadBitstreamVersion(app, c, item)
+		if !ok {
+			return
+		}
+		if bs.Current {
+			c.JSON(http.StatusConflict, gin.H{"error": "version is already current"})
+			return
+		}
+
+		before := *item
+		actor, _ := currentUser(app, c)
+		err := app.DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Model(&models.Bitstream{}).
+				Where("item_id = ? AND bundle = ? AND sequence = ?", item.ID, bs.Bundle, bs.Sequence).
+				Update("current", gorm.Expr("version = ?", bs.Version)).Error
+			if err != nil {
+				return err
+			}
+			if err := refreshItemFiles(tx, item); err != nil {
+				return err
+			}
+			if err := tx.Save(item).Error; err != nil {
+				return err
+			}
+			if err := recordItemEvent(tx, actor, EventRestore, &before, item, "restored version "+strconv.Itoa(bs.Version), nil); err != nil {
+				return err
+			}
+			if err := app.queueIndex(tx, item.ID); err != nil {
+				return err
+			}
+			return app.queueEmbed(tx, item.ID)
+		})
+		if err != nil {
+			app.Logger.Error("db restore failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore version"})
+			return
+		}
+
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+// ---------------- Migrations ----------------
+
+// MigrateBitstreams upgrades bitstream rows written before bundles existed: the
+// per-item version index is replaced and the item's version is marked current
+func (app *App) MigrateBitstreams() error {
+	if app.DB.Migrator().HasIndex(&models.Bitstream{}, "idx_bitstream_version") {
+		if err := app.DB.Migrator().DropIndex(&models.Bitstream{}, "idx_bitstream_version"); err != nil {
+			return err
+		}
+	}
+	return app.DB.Exec(`
+		UPDATE bitstreams b SET current = true
+		FROM items i
+		WHERE b.item_id = i.id AND b.version = i.version
+		  AND b.bundle = ? AND b.sequence = ? AND b.deleted_at IS NULL
+		  AND NOT EXISTS (
+		    SELECT 1 FROM bitstreams o
+		    WHERE o.item_id = b.item_id AND o.bundle = b.bundle AND o.sequence = b.sequence AND o.current
+		  )`, models.BundleOriginal, primarySequence).Error
+}
+
+// MigrateLegacyFileURLs replaces presigned links stored by older releases with the
+// stable file endpoint, recording a bitstream for the object each link pointed at
+func (app *App) MigrateLegacyFileURLs(ctx context.Context) error {
+	var items []models.Item
+	if err := app.DB.Where("file_url LIKE ?", "http%").Find(&items).Error; err != nil {
+		return err
+	}
+	for i := range items {
+		item := &items[i]
+		objectName, ok := objectNameFromURL(item.FileURL, app.Cfg.Storage.Bucket)
+		if !ok {
+			app.Logger.Warn("cannot parse legacy file url", zap.Uint("item", item.ID))
+			continue
+		}
+
+		err := app.DB.Transaction(func(tx *gorm.DB) error {
+			var count int64
+			err := tx.Model(&models.Bitstream{}).
+				Where("item_id = ? AND bundle = ? AND sequence = ? AND current", item.ID, models.BundleOriginal, primarySequence).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				info, err := app.Storage.Stat(ctx, objectName)
+				if err != nil {
+					return err
+				}
+				bs := models.Bitstream{
+					ItemID:     item.ID,
+					Bundle:     models.BundleOriginal,
+					Sequence:   primarySequence,
+					Version:    item.Version,
+					Current:    true,
+					ObjectName: objectName,
+					FileName:   path.Base(objectName),
+					Size:       info.Size,
+					MimeType:   info.ContentType,
+					UploaderID: item.SubmitterID,
+					FullText:   item.FullText,
+					LegalJSON:  item.LegalJSON,
+				}
+				if err := tx.Create(&bs).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Model(item).Update("file_url", itemFileURL(item.ID)).Error
+		})
+		if err != nil {
+			app.Logger.Warn("legacy file url migration failed", zap.Uint("item", item.ID), zap.Error(err))
+			continue
+		}
+		app.Logger.Info("migrated legacy file url", zap.Uint("item", item.ID), zap.String("object", objectName))
+	}
+	return nil
+}
+
+// objectNameFromURL extracts the object key from a path-style presigned URL
+func objectNameFromURL(raw, bucket string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	name = strings.TrimPrefix(name, bucket+"/")
+	return name, name != ""
+}
@@ -0,0 +1,47 @@
+// internal/api/bitstreams_test.go
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+)
+
+func TestFileParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	type result struct {
+		bundle string
+		seq    int
+		ok     bool
+	}
+	cases := []struct {
+		route, url string
+		want       result
+	}{
+		{"/items/:id/versions", "/items/1/versions", result{models.BundleOriginal, primarySequence, true}},
+		{"/items/:id/versions", "/items/1/versions?bundle=text&seq=2", result{models.BundleText, 2, true}},
+		{"/items/:id/versions", "/items/1/versions?seq=0", result{ok: false}},
+		{"/items/:id/versions", "/items/1/versions?bundle=SECRET", result{ok: false}},
+		{"/items/:id/bundles/:bundle/:seq/versions", "/items/1/bundles/original/3/versions", result{models.BundleOriginal, 3, true}},
+		{"/items/:id/bundles/:bundle/:seq/versions", "/items/1/bundles/original/x/versions", result{ok: false}},
+	}
+	for _, tc := range cases {
+		var got result
+		r := gin.New()
+		r.GET(tc.route, func(c *gin.Context) {
+			got.bundle, got.seq, got.ok = fileParams(c)
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.url, got, tc.want)
+		}
+		if !got.ok && w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", tc.url, w.Code)
+		}
+	}
+}
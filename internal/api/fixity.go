@@ -0,0 +1,220 @@
+// internal/api/fixity.go
+package api
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/storage"
+)
+
+// fixityMetrics is published under "fixity" on /api/admin/debug/vars
+var fixityMetrics = expvar.NewMap("fixity")
+
+// fixityFailures are the statuses reported as problems
+var fixityFailures = []string{models.FixityMismatch, models.FixityMissing, models.FixityError}
+
+// RunFixityChecker re-reads stored bitstreams in batches and compares them with
+// the checksums recorded at upload time
+func (app *App) RunFixityChecker(ctx context.Context) {
+	cfg := app.Cfg.Fixity
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := app.runFixityBatch(ctx, cfg.BatchSize); err != nil {
+			app.Logger.Error("fixity batch failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runFixityBatch verifies the bitstreams that were never checked or are due again.
+// Deleted files are included, their objects are kept so they can be restored.
+func (app *App) runFixityBatch(ctx context.Context, limit int) (map[string]int, error) {
+	var due []models.Bitstream
+	err := app.DB.Unscoped().Where("fixity_checked_at IS NULL OR fixity_checked_at < ?", time.Now().Add(-app.Cfg.Fixity.MaxAge)).
+		Order("fixity_checked_at NULLS FIRST, id").
+		Limit(limit).
+		Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		status, err := app.checkFixity(ctx, &due[i])
+		if err != nil {
+			return counts, err
+		}
+		counts[status]++
+	}
+	fixityMetrics.Set("last_run", timeVar(time.Now()))
+	return counts, nil
+}
+
+// checkFixity reads the object back, compares its digests and stores the outcome.
+// Bitstreams recorded without a checksum get one now, so later checks have a baseline.
+func (app *App) checkFixity(ctx context.Context, bs *models.Bitstream) (string, error) {
+	status, detail := models.FixityOK, ""
+
+	obj, _, err := app.Storage.Get(ctx, bs.ObjectName)
+	if err != nil {
+		status, detail = models.FixityError, err.Error()
+		if errors.Is(err, storage.ErrNotFound) {
+			status, detail = models.FixityMissing, "object not found"
+		}
+	} else {
+		sums, size, err := storage.ComputeDigests(obj)
+		obj.Close()
+		switch {
+		case err != nil:
+			status, detail = models.FixityError, err.Error()
+		case bs.Checksum == "":
+			bs.Checksum, bs.ChecksumMD5 = sums.SHA256, sums.MD5
+			app.Logger.Info("fixity baseline recorded", zap.Uint("bitstream", bs.ID))
+		default:
+			var mismatches []string
+			if sums.SHA256 != bs.Checksum {
+				mismatches = append(mismatches, fmt.Sprintf("sha256 %s != %s", sums.SHA256, bs.Checksum))
+			}
+			if bs.ChecksumMD5 != "" && sums.MD5 != bs.ChecksumMD5 {
+				mismatches = append(mismatches, fmt.Sprintf("md5 %s != %s", sums.MD5, bs.ChecksumMD5))
+			}
+			if size != bs.Size {
+				mismatches = append(mismatches, fmt.Sprintf("size %d != %d", size, bs.Size))
+			}
+			if len(mismatches) > 0 {
+				status, detail = models.FixityMismatch, strings.Join(mismatches, "; ")
+			} else if bs.ChecksumMD5 == "" {
+				bs.ChecksumMD5 = sums.MD5
+			}
+		}
+	}
+
+	now := time.Now()
+	bs.FixityStatus, bs.FixityCheckedAt, bs.FixityDetail = status, &now, detail
+	err = app.DB.Unscoped().Model(bs).Select("checksum", "checksum_md5", "fixity_status", "fixity_checked_at", "fixity_detail").Updates(bs).Error
+	if err != nil {
+		return status, err
+	}
+
+	fixityMetrics.Add("checked", 1)
+	fixityMetrics.Add(strings.ToLower(status), 1)
+	if status != models.FixityOK {
+		app.Logger.Warn("fixity check failed",
+			zap.Uint("bitstream", bs.ID),
+			zap.Uint("item", bs.ItemID),
+			zap.String("object", bs.ObjectName),
+			zap.String("status", status),
+			zap.String("detail", detail))
+	}
+	return status, nil
+}
+
+// timeVar renders a timestamp as an expvar value
+type timeVar time.Time
+
+func (t timeVar) String() string {
+	return `"` + time.Time(t).Format(time.RFC3339) + `"`
+}
+
+// ---------------- Fixity ----------------
+
+// fixityReportHandler returns bitstream counts per fixity status and a page of the
+// failing bitstreams, deleted ones included
+func fixityReportHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := parseListParams(c, "date")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var rows []struct {
+			FixityStatus string
+			Count        int64
+		}
+		err = app.DB.Unscoped().Model(&models.Bitstream{}).
+			Select("fixity_status, COUNT(*) AS count").
+			Group("fixity_status").
+			Scan(&rows).Error
+		if err != nil {
+			app.Logger.Error("db fixity summary failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load fixity report"})
+			return
+		}
+		summary := map[string]int64{}
+		for _, r := range rows {
+			summary[r.FixityStatus] = r.Count
+		}
+
+		statuses := fixityFailures
+		if s := strings.ToUpper(c.Query("status")); s != "" {
+			statuses = []string{s}
+		}
+		var total int64
+		if err := app.DB.Unscoped().Model(&models.Bitstream{}).Where("fixity_status IN ?", statuses).Count(&total).Error; err != nil {
+			app.Logger.Error("db count fixity failures failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load fixity report"})
+			return
+		}
+		var failures []models.Bitstream
+		err = app.DB.Unscoped().Where("fixity_status IN ?", statuses).
+			Order(page.orderBy(map[string]string{"date": "fixity_checked_at"})).
+			Limit(page.Limit).Offset(page.Offset).
+			Find(&failures).Error
+		if err != nil {
+			app.Logger.Error("db fixity failures failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load fixity report"})
+			return
+		}
+
+		setPageHeaders(c, page, total)
+		c.JSON(http.StatusOK, gin.H{"summary": summary, "bitstreams": failures})
+	}
+}
+
+// runFixityHandler verifies one batch immediately instead of waiting for the scheduler
+func runFixityHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		counts, err := app.runFixityBatch(c.Request.Context(), app.Cfg.Fixity.BatchSize)
+		if err != nil {
+			app.Logger.Error("fixity batch failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "fixity check failed"})
+			return
+		}
+		c.JSON(http.StatusOK, counts)
+	}
+}
+
+// checkBitstreamFixityHandler verifies a single bitstream, including a deleted one
+func checkBitstreamFixityHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var bs models.Bitstream
+		if err := app.DB.Unscoped().First(&bs, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "bitstream not found"})
+			return
+		}
+		if _, err := app.checkFixity(c.Request.Context(), &bs); err != nil {
+			app.Logger.Error("fixity check failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "fixity check failed"})
+			return
+		}
+		c.JSON(http.StatusOK, bs)
+	}
+}
@@ -3,24 +3,19 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-
-	"github.com/ledongthuc/pdf"
+	"gorm.io/gorm"
 
 	"github.com/mohan2020coder/mSpace/internal/models"
-	"github.com/mohan2020coder/mSpace/internal/search"
+	"github.com/mohan2020coder/mSpace/internal/workflow"
 )
 
 // type createItemReq struct {
@@ -53,37 +48,57 @@ import (
 // ---------------- Items ----------------
 
 type createItemReq struct {
-	Title        string `json:"title" binding:"required"`
-	Author       string `json:"author"`
-	Abstract     string `json:"abstract"`
-	CollectionID uint   `json:"collection_id" binding:"required"`
-	Visibility   string `json:"visibility"` // PUBLIC/PRIVATE
-	LegalJSON    string `json:"legal_json"` // optional
+	Title        string     `json:"title" binding:"required"`
+	Author       string     `json:"author"`
+	Abstract     string     `json:"abstract"`
+	CollectionID uint       `json:"collection_id" binding:"required"`
+	Visibility   string     `json:"visibility" binding:"omitempty,oneof=PUBLIC PRIVATE"` // defaults to PUBLIC
+	EmbargoUntil *time.Time `json:"embargo_until"`                                       // optional, only for PRIVATE
+	LegalJSON    string     `json:"legal_json"`                                          // optional
 }
 
 func listItemsHandler(app *App) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		access, err := resolveAccess(app, c)
+		if err != nil {
+			app.Logger.Error("resolve access failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+			return
+		}
+		page, err := parseListParams(c, "date", "title")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var total int64
+		if err := app.DB.Model(&models.Item{}).Scopes(visibleItems(access)).Count(&total).Error; err != nil {
+			app.Logger.Error("db count failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list items"})
+			return
+		}
 		var items []models.Item
-		if err := app.DB.Order("created_at desc").Find(&items).Error; err != nil {
+		err = app.DB.Scopes(visibleItems(access)).
+			Order(page.orderBy(map[string]string{"date": "created_at", "title": "title"})).
+			Limit(page.Limit).Offset(page.Offset).
+			Find(&items).Error
+		if err != nil {
 			app.Logger.Error("db list failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list items"})
 			return
 		}
+		setPageHeaders(c, page, total)
 		c.JSON(http.StatusOK, items)
 	}
 }
 
 func getItemHandler(app *App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		idStr := c.Param("id")
-		id, err := strconv.Atoi(idStr)
-		if err != nil {
+		if _, err := strconv.Atoi(c.Param("id")); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 			return
 		}
-		var item models.Item
-		if err := app.DB.First(&item, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		item, ok := loadVisibleItem(app, c)
+		if !ok {
 			return
 		}
 		c.JSON(http.StatusOK, item)
@@ -124,7 +139,7 @@ func getItemHandler(app *App) gin.HandlerFunc {
 // 		// Upload to minio
 // 		ctx := context.Background()
 // 		if _, err := app.Minio.UploadStream(ctx, objectName, src, fh.Size, fh.Header.Get("Content-Type")); err != nil {
-// 			app.Logger.Error("minio upload failed", zap.Error(err))
+// 			app.Logger.Error("storage upload failed", zap.Error(err))
 // 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload file"})
 // 			return
 // 		}
@@ -186,12 +201,28 @@ func createCommunityHandler(app *App) gin.HandlerFunc {
 
 func listCommunitiesHandler(app *App) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		page, err := parseListParams(c, "title", "date")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var total int64
+		if err := app.DB.Model(&models.Community{}).Count(&total).Error; err != nil {
+			app.Logger.Error("db count communities failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list communities"})
+			return
+		}
 		var communities []models.Community
-		if err := app.DB.Preload("Collections").Find(&communities).Error; err != nil {
+		err = app.DB.Preload("Collections").
+			Order(page.orderBy(map[string]string{"date": "created_at", "title": "name"})).
+			Limit(page.Limit).Offset(page.Offset).
+			Find(&communities).Error
+		if err != nil {
 			app.Logger.Error("db list communities failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list communities"})
 			return
 		}
+		setPageHeaders(c, page, total)
 		c.JSON(http.StatusOK, communities)
 	}
 }
@@ -205,6 +236,9 @@ func createCollectionHandler(app *App) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if !authorizeCommunity(app, c, collection.CommunityID, models.ActionAdmin) {
+			return
+		}
 		if err := app.DB.Create(&collection).Error; err != nil {
 			app.Logger.Error("db create collection failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create collection"})
@@ -216,12 +250,28 @@ func createCollectionHandler(app *App) gin.HandlerFunc {
 
 func listCollectionsHandler(app *App) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		page, err := parseListParams(c, "title", "date")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var total int64
+		if err := app.DB.Model(&models.Collection{}).Count(&total).Error; err != nil {
+			app.Logger.Error("db count collections failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list collections"})
+			return
+		}
+		// Items are listed per collection through /api/items and /api/search, not embedded here
 		var collections []models.Collection
-		if err := app.DB.Preload("Items").Find(&collections).Error; err != nil {
+		err = app.DB.Order(page.orderBy(map[string]string{"date": "created_at", "title": "name"})).
+			Limit(page.Limit).Offset(page.Offset).
+			Find(&collections).Error
+		if err != nil {
 			app.Logger.Error("db list collections failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list collections"})
 			return
 		}
+		setPageHeaders(c, page, total)
 		c.JSON(http.StatusOK, collections)
 	}
 }
@@ -233,6 +283,19 @@ func createItemHandler(app *App) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if !authorizeCollection(app, c, req.CollectionID, models.ActionSubmit) {
+			return
+		}
+		user, _ := currentUser(app, c)
+
+		visibility := req.Visibility
+		if visibility == "" {
+			visibility = models.VisibilityPublic
+		}
+		if req.EmbargoUntil != nil && visibility != models.VisibilityPrivate {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "embargo_until requires PRIVATE visibility"})
+			return
+		}
 
 		// Initialize item
 		item := models.Item{
@@ -240,14 +303,28 @@ func createItemHandler(app *App) gin.HandlerFunc {
 			Author:       req.Author,
 			Abstract:     req.Abstract,
 			CollectionID: req.CollectionID,
-			Status:       "DRAFT",
+			Status:       workflow.StateDraft,
 			Version:      0,
-			Visibility:   req.Visibility,
+			Visibility:   visibility,
+			EmbargoUntil: req.EmbargoUntil,
+			SubmitterID:  user.ID,
 			FullText:     "",   // fine as empty string
 			LegalJSON:    "{}", // must be valid JSON
 		}
 
-		if err := app.DB.Create(&item).Error; err != nil {
+		err := app.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&item).Error; err != nil {
+				return err
+			}
+			if err := recordItemEvent(tx, user, EventCreate, nil, &item, "", nil); err != nil {
+				return err
+			}
+			if err := app.queueIndex(tx, item.ID); err != nil {
+				return err
+			}
+			return app.queueEmbed(tx, item.ID)
+		})
+		if err != nil {
 			app.Logger.Error("db create item failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create item"})
 			return
@@ -257,10 +334,163 @@ func createItemHandler(app *App) gin.HandlerFunc {
 	}
 }
 
+// deleteItemHandler withdraws an item from the repository (soft delete) and drops it
+// from the search index. Stored files are kept. Collection admins only.
+func deleteItemHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		if !authorizeCollection(app, c, item.CollectionID, models.ActionAdmin) {
+			return
+		}
+		actor, _ := currentUser(app, c)
+		err := app.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(item).Error; err != nil {
+				return err
+			}
+			if err := recordItemEvent(tx, actor, EventDelete, item, item, "", nil); err != nil {
+				return err
+			}
+			if err := app.queueIndex(tx, item.ID); err != nil {
+				return err
+			}
+			return app.queueEmbed(tx, item.ID)
+		})
+		if err != nil {
+			app.Logger.Error("db delete item failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete item"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+type updateItemReq struct {
+	Title        *string           `json:"title"`
+	Author       *string           `json:"author"`
+	Abstract     *string           `json:"abstract"`
+	Visibility   *string           `json:"visibility" binding:"omitempty,oneof=PUBLIC PRIVATE"`
+	EmbargoUntil *time.Time        `json:"embargo_until"`
+	Metadata     map[string]string `json:"metadata"` // empty value removes the key
+}
+
+// updateItemHandler edits descriptive metadata. Submitters may edit while the item is
+// DRAFT or CHANGES_REQUESTED, collection admins at any time.
+func updateItemHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		user, ok := requireUser(app, c)
+		if !ok {
+			return
+		}
+		isAdmin, err := app.canCollection(user, item.CollectionID, models.ActionAdmin)
+		if err != nil {
+			app.Logger.Error("permission check failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+			return
+		}
+		if !isAdmin {
+			if item.SubmitterID != user.ID {
+				c.JSON(http.StatusForbidden, gin.H{"error": "only the submitter or a collection admin may edit this item"})
+				return
+			}
+			if !workflow.Editable(item.Status) {
+				c.JSON(http.StatusConflict, gin.H{"error": "item can only be edited while DRAFT or CHANGES_REQUESTED"})
+				return
+			}
+		}
+
+		var req updateItemReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		before := *item
+		if req.Title != nil {
+			item.Title = *req.Title
+		}
+		if req.Author != nil {
+			item.Author = *req.Author
+		}
+		if req.Abstract != nil {
+			item.Abstract = *req.Abstract
+		}
+		if req.Visibility != nil {
+			item.Visibility = *req.Visibility
+		}
+		if req.EmbargoUntil != nil {
+			item.EmbargoUntil = req.EmbargoUntil
+		}
+		if req.EmbargoUntil != nil && item.Visibility != models.VisibilityPrivate {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "embargo_until requires PRIVATE visibility"})
+			return
+		}
+
+		var existing []models.Metadata
+		if err := app.DB.Where("item_id = ?", item.ID).Find(&existing).Error; err != nil {
+			app.Logger.Error("db load metadata failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update item"})
+			return
+		}
+		current := map[string]models.Metadata{}
+		for _, m := range existing {
+			current[m.Key] = m
+		}
+		metaChanges := map[string]fieldChange{}
+
+		err = app.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(item).Error; err != nil {
+				return err
+			}
+			for key, value := range req.Metadata {
+				old, exists := current[key]
+				switch {
+				case exists && value == "":
+					if err := tx.Delete(&old).Error; err != nil {
+						return err
+					}
+				case exists && old.Value != value:
+					if err := tx.Model(&old).Update("value", value).Error; err != nil {
+						return err
+					}
+				case !exists && value != "":
+					if err := tx.Create(&models.Metadata{ItemID: item.ID, Key: key, Value: value}).Error; err != nil {
+						return err
+					}
+				default:
+					continue
+				}
+				metaChanges["metadata."+key] = fieldChange{Old: old.Value, New: value}
+			}
+			if err := recordItemEvent(tx, user, EventUpdate, &before, item, "", metaChanges); err != nil {
+				return err
+			}
+			if err := app.queueIndex(tx, item.ID); err != nil {
+				return err
+			}
+			return app.queueEmbed(tx, item.ID)
+		})
+		if err != nil {
+			app.Logger.Error("db update item failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update item"})
+			return
+		}
+
+		app.DB.Where("item_id = ?", item.ID).Find(&item.Metadata)
+		c.JSON(http.StatusOK, item)
+	}
+}
+
 // Upload file with versioning
 
 // uploadFileHandler handles file upload + extraction + indexing
-// func uploadFileHandler(app *App, searchIndex *search.SearchIndex) gin.HandlerFunc {
+// func uploadFileHandler(app *App) gin.HandlerFunc {
 // 	return func(c *gin.Context) {
 // 		// --- Parse item ID ---
 // 		idStr := c.Param("id")
@@ -299,7 +529,7 @@ func createItemHandler(app *App) gin.HandlerFunc {
 // 		// --- Upload to MinIO ---
 // 		ctx := context.Background()
 // 		if _, err := app.Minio.UploadStream(ctx, objectName, src, fh.Size, fh.Header.Get("Content-Type")); err != nil {
-// 			app.Logger.Error("minio upload failed", zap.Error(err))
+// 			app.Logger.Error("storage upload failed", zap.Error(err))
 // 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload file"})
 // 			return
 // 		}
@@ -351,7 +581,7 @@ func createItemHandler(app *App) gin.HandlerFunc {
 //			})
 //		}
 //	}
-func uploadFileHandler(app *App, searchIndex *search.SearchIndex) gin.HandlerFunc {
+func uploadFileHandler(app *App) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// --- Parse item ID ---
 		idStr := c.Param("id")
@@ -367,6 +597,22 @@ func uploadFileHandler(app *App, searchIndex *search.SearchIndex) gin.HandlerFun
 			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
 			return
 		}
+		if !authorizeItemFiles(app, c, &item) {
+			return
+		}
+
+		// --- Target bundle + position ---
+		bundle := strings.ToUpper(c.DefaultPostForm("bundle", models.BundleOriginal))
+		if !validBundle(bundle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bundle must be one of ORIGINAL, TEXT, THUMBNAIL, LICENSE"})
+			return
+		}
+		appendNew, _ := strconv.ParseBool(c.PostForm("append"))
+		sequence, err := app.targetSequence(item.ID, bundle, c.PostForm("sequence"), appendNew)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		// --- Get uploaded file ---
 		fh, err := c.FormFile("file")
@@ -382,185 +628,98 @@ func uploadFileHandler(app *App, searchIndex *search.SearchIndex) gin.HandlerFun
 		}
 		defer src.Close()
 
-		// --- Increment version + object name ---
-		item.Version++
-		ext := strings.ToLower(filepath.Ext(fh.Filename))
-		objectName := fmt.Sprintf("item-%d-v%d-%d%s", item.ID, item.Version, time.Now().Unix(), ext)
+		actor, _ := currentUser(app, c)
 
-		// --- Upload to MinIO ---
+		// --- Upload to storage, hashing on the way ---
 		ctx := context.Background()
-		if _, err := app.Minio.UploadStream(ctx, objectName, src, fh.Size, fh.Header.Get("Content-Type")); err != nil {
-			app.Logger.Error("minio upload failed", zap.Error(err))
+		bitstream, err := app.putBitstream(ctx, item.ID, bundle, sequence, fh.Filename, detectMimeType(fh), src, fh.Size, actor.ID)
+		if err != nil {
+			app.Logger.Error("storage upload failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload file"})
 			return
 		}
 
-		url, err := app.Minio.PresignedURL(ctx, objectName, 24*time.Hour)
+		job, err := app.attachBitstream(&item, bitstream, actor)
 		if err != nil {
-			app.Logger.Error("presign failed", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get file url"})
-			return
-		}
-
-		item.FileURL = url
-		item.Status = "SUBMITTED"
-
-		// --- Handle PDF extraction ---
-		if ext == ".pdf" {
-			tempDir := "./temp"
-			os.MkdirAll(tempDir, 0755)
-			tempPath := filepath.Join(tempDir, fmt.Sprintf("%d_%s", time.Now().UnixNano(), fh.Filename))
-
-			if err := c.SaveUploadedFile(fh, tempPath); err == nil {
-				app.Logger.Info("Saved PDF to temp", zap.String("path", tempPath), zap.Int64("size", fh.Size))
-
-				fullText := ExtractPDFTextWithTikaFallback(tempPath, app.Logger)
-				app.Logger.Info("Extracted PDF text", zap.Int("length", len(fullText)))
-
-				item.FullText = fullText
-
-				// --- Parse structured legal document ---
-				// legalDoc := search.ParseLegalDocument(fullText)
-				// item.LegalJSON, _ = json.Marshal(legalDoc)
-
-				legalDoc := search.ParseLegalDocument(fullText)
-				b, _ := json.Marshal(legalDoc)
-				item.LegalJSON = string(b) // store as plain JSON string in DB
-
-				os.Remove(tempPath)
-			} else {
-				app.Logger.Error("Failed to save uploaded file", zap.Error(err))
-			}
-		}
-
-		// --- Save item to DB ---
-		if err := app.DB.Save(&item).Error; err != nil {
 			app.Logger.Error("db update failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update item"})
 			return
 		}
 
-		// --- Index item in Bleve ---
-		if err := searchIndex.IndexItem(&item); err != nil {
-			app.Logger.Error("bleve index failed", zap.Error(err))
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"message":  "file uploaded",
-			"file_url": url,
-		})
+		c.JSON(http.StatusAccepted, uploadResponse(bitstream, job))
 	}
 }
 
-// --- PDF Extraction helpers with logs ---
-
-func ExtractPDFTextWithTikaFallback(filePath string, logger *zap.Logger) string {
-	text := extractWithLedongthuc(filePath, logger)
-	if len(strings.TrimSpace(text)) < 10 {
-		logger.Info("Digital text extraction empty, falling back to Tika OCR")
-		return extractWithTika(filePath, logger)
-	}
-	return text
-}
-
-func extractWithLedongthuc(filePath string, logger *zap.Logger) string {
-	logger.Info("Starting digital extraction", zap.String("file", filePath))
-	f, r, err := pdf.Open(filePath)
-	if err != nil {
-		logger.Error("Failed to open PDF", zap.Error(err))
-		return ""
-	}
-	defer f.Close()
-
-	var content strings.Builder
-	for i := 1; i <= r.NumPage(); i++ {
-		page := r.Page(i)
-		if page.V.IsNull() {
-			continue
-		}
-		text, err := page.GetPlainText(nil)
-		if err != nil {
-			logger.Warn("Failed page extraction", zap.Int("page", i), zap.Error(err))
-			continue
-		}
-		content.WriteString(text + "\n")
-	}
-
-	lines := strings.Split(content.String(), "\n")
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-		line = strings.Join(strings.Fields(line), " ")
-		lines[i] = line
+// uploadResponse describes a stored file whose text extraction and indexing run in job
+func uploadResponse(bs *models.Bitstream, job *models.Job) gin.H {
+	return gin.H{
+		"message":  "file uploaded, processing",
+		"file_url": bundleFileURL(bs),
+		"bundle":   bs.Bundle,
+		"sequence": bs.Sequence,
+		"version":  bs.Version,
+		"checksum": bs.Checksum,
+		"job_id":   job.ID,
+		"job_url":  fmt.Sprintf("/api/jobs/%d", job.ID),
 	}
-
-	extracted := strings.Join(lines, "\n")
-	logger.Info("Digital extraction completed", zap.Int("length", len(extracted)))
-	return extracted
-}
-
-func extractWithTika(filePath string, logger *zap.Logger) string {
-	logger.Info("Starting Tika OCR extraction", zap.String("file", filePath))
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		logger.Error("Failed to open PDF for Tika", zap.Error(err))
-		return ""
-	}
-	defer file.Close()
-
-	// Tika endpoint expects PUT or POST with Accept: text/plain
-	req, _ := http.NewRequest("PUT", "http://localhost:9998/tika", file)
-	req.Header.Set("Accept", "text/plain")
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		logger.Error("Tika request failed", zap.Error(err))
-		return ""
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	text := strings.TrimSpace(string(body))
-	logger.Info("Tika OCR completed", zap.Int("length", len(text)))
-
-	if len(text) == 0 {
-		logger.Warn("Tika OCR returned empty text, check Tesseract installation")
-	}
-	return text
 }
 
 // ---------------- Workflow ----------------
 
+// publishItemHandler approves the current reviewer step; the last approval publishes the item
 func publishItemHandler(app *App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		idStr := c.Param("id")
-		id, _ := strconv.Atoi(idStr)
-		var item models.Item
-		if err := app.DB.First(&item, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		req, ok := bindReview(c, false)
+		if !ok {
 			return
 		}
-		item.Status = "PUBLISHED"
-		if err := app.DB.Save(&item).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to publish item"})
+		steps, err := app.workflowSteps(item.CollectionID)
+		if err != nil {
+			app.Logger.Error("db load workflow failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load workflow"})
+			return
+		}
+		step := reviewStepFor(item)
+		if !authorizeReviewStep(app, c, item, steps, step) {
+			return
+		}
+
+		action, next, nextStep := EventPublish, workflow.StatePublished, 0
+		if item.Status == workflow.StateInReview && step < len(steps) {
+			action, next, nextStep = EventApprove, workflow.StateInReview, step+1
+		}
+		if !transitionItem(app, c, item, action, next, nextStep, req.Comment) {
 			return
 		}
 		c.JSON(http.StatusOK, item)
 	}
 }
 
+// rejectItemHandler rejects a submitted or in-review item; a reviewer comment is mandatory
 func rejectItemHandler(app *App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		idStr := c.Param("id")
-		id, _ := strconv.Atoi(idStr)
-		var item models.Item
-		if err := app.DB.First(&item, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		req, ok := bindReview(c, true)
+		if !ok {
+			return
+		}
+		steps, err := app.workflowSteps(item.CollectionID)
+		if err != nil {
+			app.Logger.Error("db load workflow failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load workflow"})
+			return
+		}
+		if !authorizeReviewStep(app, c, item, steps, reviewStepFor(item)) {
 			return
 		}
-		item.Status = "REJECTED"
-		if err := app.DB.Save(&item).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reject item"})
+		if !transitionItem(app, c, item, EventReject, workflow.StateRejected, 0, req.Comment) {
 			return
 		}
 		c.JSON(http.StatusOK, item)
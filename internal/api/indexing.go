@@ -0,0 +1,263 @@
+// internal/api/indexing.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/jobs"
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/search"
+)
+
+// Index maintenance jobs
+const (
+	JobIndexItem  = "index_item"  // reindex one item, or drop it from the index once deleted
+	JobReindexAll = "reindex_all" // rebuild the whole index from the database
+)
+
+// reindexBatchSize is the number of items loaded and indexed per batch while reindexing
+const reindexBatchSize = 200
+
+type indexPayload struct {
+	ItemID uint `json:"item_id"`
+}
+
+// queueIndex schedules reindexing of the item using tx, so the index follows the
+// change only once it commits
+func (app *App) queueIndex(tx *gorm.DB, itemID uint) error {
+	_, err := app.Jobs.Enqueue(tx, JobIndexItem, &itemID, indexPayload{ItemID: itemID})
+	return err
+}
+
+// indexItem indexes the item together with the page texts of its current original files
+func (app *App) indexItem(searchIndex *search.SearchIndex, item *models.Item) error {
+	pages, err := app.itemPages([]uint{item.ID})
+	if err != nil {
+		return err
+	}
+	return searchIndex.IndexItem(item, pages[item.ID])
+}
+
+// itemPages loads the page texts of the current original files of the given items
+func (app *App) itemPages(itemIDs []uint) (map[uint][]search.BlevePage, error) {
+	var rows []struct {
+		ItemID uint
+		search.BlevePage
+	}
+	err := app.DB.Table("bitstream_pages").
+		Select("bitstreams.item_id, bitstreams.sequence, bitstream_pages.page, bitstream_pages.text").
+		Joins("JOIN bitstreams ON bitstreams.id = bitstream_pages.bitstream_id").
+		Where("bitstreams.item_id IN ? AND bitstreams.bundle = ? AND bitstreams.current AND bitstreams.deleted_at IS NULL", itemIDs, models.BundleOriginal).
+		Order("bitstreams.item_id, bitstreams.sequence, bitstream_pages.page").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	pages := map[uint][]search.BlevePage{}
+	for _, r := range rows {
+		pages[r.ItemID] = append(pages[r.ItemID], r.BlevePage)
+	}
+	return pages, nil
+}
+
+// indexItems indexes a batch of items in one index batch
+func (app *App) indexItems(searchIndex *search.SearchIndex, items []models.Item) error {
+	ids := make([]uint, len(items))
+	for i := range items {
+		ids[i] = items[i].ID
+	}
+	pages, err := app.itemPages(ids)
+	if err != nil {
+		return err
+	}
+	docs := make([]search.ItemDoc, len(items))
+	for i := range items {
+		docs[i] = search.ItemDoc{Item: &items[i], Pages: pages[items[i].ID]}
+	}
+	return searchIndex.IndexItems(docs)
+}
+
+// ReindexAll indexes every item from the database in batches. It returns the number
+// of items indexed; documents of deleted items are left to reconcileIndex.
+func (app *App) ReindexAll(ctx context.Context, searchIndex *search.SearchIndex) (int, error) {
+	indexed := 0
+	var batch []models.Item
+	err := app.DB.Order("id").FindInBatches(&batch, reindexBatchSize, func(tx *gorm.DB, _ int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := app.indexItems(searchIndex, batch); err != nil {
+			return fmt.Errorf("index items %d-%d: %w", batch[0].ID, batch[len(batch)-1].ID, err)
+		}
+		indexed += len(batch)
+		return nil
+	}).Error
+	app.Logger.Info("reindex finished", zap.Int("items", indexed), zap.Error(err))
+	return indexed, err
+}
+
+// ---------------- Reconciliation ----------------
+
+// IndexDrift lists the differences found between the database and the index
+type IndexDrift struct {
+	Items    int    `json:"items"`    // items in the database
+	Indexed  int    `json:"indexed"`  // documents in the index
+	Missing  []uint `json:"missing"`  // items not in the index
+	Stale    []uint `json:"stale"`    // items changed after they were indexed
+	Orphaned []uint `json:"orphaned"` // documents whose item no longer exists
+	Fixed    bool   `json:"fixed"`
+}
+
+// reconcileIndex compares item ids and update times in the database with the index;
+// with fix set, missing and stale items are reindexed and orphaned documents deleted
+func (app *App) reconcileIndex(ctx context.Context, searchIndex *search.SearchIndex, fix bool) (*IndexDrift, error) {
+	indexed, err := searchIndex.IndexedItems()
+	if err != nil {
+		return nil, err
+	}
+	drift := &IndexDrift{Indexed: len(indexed), Missing: []uint{}, Stale: []uint{}, Orphaned: []uint{}}
+
+	var rows []models.Item
+	err = app.DB.Select("id", "updated_at").Order("id").
+		FindInBatches(&rows, 1000, func(tx *gorm.DB, _ int) error {
+			for _, r := range rows {
+				drift.Items++
+				at, ok := indexed[r.ID]
+				delete(indexed, r.ID)
+				switch {
+				case !ok:
+					drift.Missing = append(drift.Missing, r.ID)
+				case r.UpdatedAt.Truncate(time.Second).After(at): // the index keeps whole seconds
+					drift.Stale = append(drift.Stale, r.ID)
+				}
+			}
+			return ctx.Err()
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	for id := range indexed {
+		drift.Orphaned = append(drift.Orphaned, id)
+	}
+
+	if !fix {
+		return drift, nil
+	}
+	for _, id := range drift.Orphaned {
+		if err := searchIndex.DeleteItem(id); err != nil {
+			return drift, err
+		}
+	}
+	outdated := append(append([]uint(nil), drift.Missing...), drift.Stale...)
+	for start := 0; start < len(outdated); start += reindexBatchSize {
+		ids := outdated[start:min(start+reindexBatchSize, len(outdated))]
+		var items []models.Item
+		if err := app.DB.Where("id IN ?", ids).Find(&items).Error; err != nil {
+			return drift, err
+		}
+		if err := app.indexItems(searchIndex, items); err != nil {
+			return drift, err
+		}
+	}
+	drift.Fixed = true
+	return drift, nil
+}
+
+// RunIndexReconciler periodically repairs drift between the database and the index
+func (app *App) RunIndexReconciler(ctx context.Context, searchIndex *search.SearchIndex) {
+	ticker := time.NewTicker(app.Cfg.Search.ReconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		drift, err := app.reconcileIndex(ctx, searchIndex, true)
+		if err != nil {
+			app.Logger.Error("index reconciliation failed", zap.Error(err))
+			continue
+		}
+		if n := len(drift.Missing) + len(drift.Stale) + len(drift.Orphaned); n > 0 {
+			app.Logger.Warn("index drift repaired",
+				zap.Int("missing", len(drift.Missing)),
+				zap.Int("stale", len(drift.Stale)),
+				zap.Int("orphaned", len(drift.Orphaned)))
+		}
+	}
+}
+
+// ---------------- Jobs ----------------
+
+// indexItemJob brings one item's document in line with the database
+func (app *App) indexItemJob(searchIndex *search.SearchIndex) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload indexPayload
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return jobs.Permanent(err)
+		}
+		var item models.Item
+		err := app.DB.First(&item, payload.ItemID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return searchIndex.DeleteItem(payload.ItemID)
+		}
+		if err != nil {
+			return err
+		}
+		return app.indexItem(searchIndex, &item)
+	}
+}
+
+// reindexAllJob rebuilds the index from the database and drops documents of deleted items
+func (app *App) reindexAllJob(searchIndex *search.SearchIndex) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) error {
+		if _, err := app.ReindexAll(ctx, searchIndex); err != nil {
+			return err
+		}
+		_, err := app.reconcileIndex(ctx, searchIndex, true)
+		return err
+	}
+}
+
+// ---------------- Handlers ----------------
+
+// reindexHandler queues a full rebuild of the search index
+func reindexHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := app.Jobs.Enqueue(app.DB, JobReindexAll, nil, struct{}{})
+		if err != nil {
+			app.Logger.Error("db enqueue reindex failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue reindex"})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "reindex queued",
+			"job_id":  job.ID,
+			"job_url": fmt.Sprintf("/api/jobs/%d", job.ID),
+		})
+	}
+}
+
+// indexDriftHandler reports drift between the database and the index on GET and
+// repairs it on POST
+func indexDriftHandler(app *App, searchIndex *search.SearchIndex) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fix := c.Request.Method == http.MethodPost
+		drift, err := app.reconcileIndex(c.Request.Context(), searchIndex, fix)
+		if err != nil {
+			app.Logger.Error("index reconciliation failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "index reconciliation failed"})
+			return
+		}
+		c.JSON(http.StatusOK, drift)
+	}
+}
@@ -0,0 +1,290 @@
+// internal/api/ingest.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mohan2020coder/mSpace/internal/extract"
+	"github.com/mohan2020coder/mSpace/internal/jobs"
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/search"
+)
+
+// JobIngestBitstream extracts text from an uploaded file and reindexes its item
+const JobIngestBitstream = "ingest_bitstream"
+
+type ingestPayload struct {
+	BitstreamID uint `json:"bitstream_id"`
+}
+
+// attachBitstream makes an already stored bitstream the current version of its file,
+// marks the item PROCESSING and queues the extraction job, all in one transaction
+func (app *App) attachBitstream(item *models.Item, bitstream *models.Bitstream, actor *models.User) (*models.Job, error) {
+	before := *item
+	var job *models.Job
+	err := app.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveBitstream(tx, bitstream); err != nil {
+			return err
+		}
+		if err := refreshItemFiles(tx, item); err != nil {
+			return err
+		}
+		item.Processing, item.ProcessingError = models.ProcessingPending, ""
+		if err := tx.Save(item).Error; err != nil {
+			return err
+		}
+		if err := recordItemEvent(tx, actor, EventUpload, &before, item, fileLabel(bitstream), nil); err != nil {
+			return err
+		}
+		var err error
+		job, err = app.Jobs.Enqueue(tx, JobIngestBitstream, &item.ID, ingestPayload{BitstreamID: bitstream.ID})
+		return err
+	})
+	return job, err
+}
+
+// RegisterJobs installs the job handlers that need the search index
+func (app *App) RegisterJobs(searchIndex *search.SearchIndex) {
+	app.Jobs.Register(JobIngestBitstream, app.ingestBitstreamJob(searchIndex))
+	app.Jobs.Register(JobIndexItem, app.indexItemJob(searchIndex))
+	app.Jobs.Register(JobReindexAll, app.reindexAllJob(searchIndex))
+	app.Jobs.Register(JobEmbedItem, app.embedItemJob())
+	app.Jobs.Register(JobEmbedAll, app.embedAllJob())
+	app.Jobs.OnFailure = app.markIngestFailed
+}
+
+// ingestBitstreamJob extracts text from deposited files (read back from storage) with the
+// extractor registered for their format, keeps it as a derived TEXT file, refreshes the
+// item's file fields, reindexes the item and queues its embedding
+func (app *App) ingestBitstreamJob(searchIndex *search.SearchIndex) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload ingestPayload
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return jobs.Permanent(err)
+		}
+		var bitstream models.Bitstream
+		if err := app.DB.First(&bitstream, payload.BitstreamID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return jobs.Permanent(fmt.Errorf("bitstream %d no longer exists", payload.BitstreamID))
+			}
+			return err
+		}
+
+		// --- Extract text from deposited files ---
+		var derived *models.Bitstream
+		var pages []models.BitstreamPage
+		extractor, ok := app.Extractors.Lookup(bitstream.MimeType, bitstream.FileName)
+		if bitstream.Bundle == models.BundleOriginal && !ok {
+			app.Logger.Info("no text extractor for file", zap.Uint("bitstream", bitstream.ID), zap.String("mime", bitstream.MimeType))
+		}
+		if bitstream.Bundle == models.BundleOriginal && ok {
+			obj, info, err := app.Storage.Get(ctx, bitstream.ObjectName)
+			if err != nil {
+				return fmt.Errorf("read stored file: %w", err)
+			}
+			text, err := extractor.Extract(ctx, obj, info.Size)
+			obj.Close()
+			if err != nil {
+				err = fmt.Errorf("extract text from %s: %w", bitstream.FileName, err)
+				app.recordExtractionError(&bitstream, err)
+				if errors.Is(err, extract.ErrTooLarge) || errors.Is(err, extract.ErrUnprocessable) {
+					return jobs.Permanent(err)
+				}
+				return err
+			}
+			fullText := text.String()
+			app.Logger.Info("Extracted text", zap.Uint("bitstream", bitstream.ID), zap.String("mime", bitstream.MimeType),
+				zap.Int("length", len(fullText)), zap.Int("pages", len(text.Pages)))
+
+			bitstream.FullText, bitstream.ExtractionError = fullText, ""
+			for i, p := range text.Pages {
+				if strings.TrimSpace(p) != "" {
+					pages = append(pages, models.BitstreamPage{BitstreamID: bitstream.ID, Page: i + 1, Text: p})
+				}
+			}
+
+			// --- Parse structured legal document ---
+			legalDoc := search.ParseLegalDocument(fullText)
+			b, _ := json.Marshal(legalDoc)
+			bitstream.LegalJSON = string(b) // store as plain JSON string in DB
+
+			// --- Keep the extracted text as a derived TEXT file ---
+			if strings.TrimSpace(fullText) != "" {
+				derived, err = app.putBitstream(ctx, bitstream.ItemID, models.BundleText, bitstream.Sequence, bitstream.FileName+".txt",
+					"text/plain; charset=utf-8", strings.NewReader(fullText), int64(len(fullText)), bitstream.UploaderID)
+				if err != nil {
+					return fmt.Errorf("store extracted text: %w", err)
+				}
+			}
+		}
+
+		// --- Save extraction results and derived item fields ---
+		var item models.Item
+		stale := false
+		err := app.DB.Transaction(func(tx *gorm.DB) error {
+			// A newer upload, a restore or a removal may have replaced the file while it
+			// was extracted; its text must not become the current text then
+			var latest models.Bitstream
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&latest, bitstream.ID).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err != nil || !latest.Current || latest.Version != bitstream.Version {
+				stale = true
+				return app.settleProcessing(tx, &item, bitstream.ItemID, job.ID)
+			}
+
+			err = tx.Model(&bitstream).Select("full_text", "legal_json", "extraction_error").Updates(&bitstream).Error
+			if err != nil {
+				return err
+			}
+			if bitstream.Bundle == models.BundleOriginal {
+				if err := replacePages(tx, bitstream.ID, pages); err != nil {
+					return err
+				}
+			}
+			if derived != nil {
+				if err := saveBitstream(tx, derived); err != nil {
+					return err
+				}
+			}
+			if err := tx.First(&item, bitstream.ItemID).Error; err != nil {
+				return err
+			}
+			if err := refreshItemFiles(tx, &item); err != nil {
+				return err
+			}
+			pending, err := app.Jobs.Pending(JobIngestBitstream, item.ID, job.ID)
+			if err != nil {
+				return err
+			}
+			if !pending && item.Processing == models.ProcessingPending {
+				item.Processing, item.ProcessingError = models.ProcessingReady, ""
+			}
+			err = tx.Model(&item).
+				Select("version", "file_url", "full_text", "legal_json", "processing", "processing_error").
+				Updates(&item).Error
+			if err != nil || pending {
+				return err
+			}
+			// Embed once the item's last pending file is in
+			return app.queueEmbed(tx, item.ID)
+		})
+		if err != nil {
+			return err
+		}
+		if stale {
+			// The stored text object is left alone: the job of the newer file may have
+			// written the same object name
+			app.Logger.Info("file replaced during extraction, discarding its text", zap.Uint("bitstream", bitstream.ID))
+			return nil
+		}
+
+		// --- Index item in Bleve ---
+		if err := app.indexItem(searchIndex, &item); err != nil {
+			return fmt.Errorf("index item %d: %w", item.ID, err)
+		}
+		return nil
+	}
+}
+
+// settleProcessing marks the item ready when no other ingest job of it is pending
+func (app *App) settleProcessing(tx *gorm.DB, item *models.Item, itemID, jobID uint) error {
+	if err := tx.First(item, itemID).Error; err != nil {
+		return err
+	}
+	pending, err := app.Jobs.Pending(JobIngestBitstream, itemID, jobID)
+	if err != nil || pending || item.Processing != models.ProcessingPending {
+		return err
+	}
+	item.Processing, item.ProcessingError = models.ProcessingReady, ""
+	return tx.Model(item).Select("processing", "processing_error").Updates(item).Error
+}
+
+// replacePages swaps the stored page texts of a bitstream for a fresh extraction
+func replacePages(tx *gorm.DB, bitstreamID uint, pages []models.BitstreamPage) error {
+	if err := tx.Where("bitstream_id = ?", bitstreamID).Delete(&models.BitstreamPage{}).Error; err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(pages, 100).Error
+}
+
+// recordExtractionError shows a failed attempt on the bitstream and its item while the job retries
+func (app *App) recordExtractionError(bitstream *models.Bitstream, err error) {
+	bitstream.ExtractionError = err.Error()
+	dbErr := app.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(bitstream).Update("extraction_error", bitstream.ExtractionError).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Item{}).Where("id = ?", bitstream.ItemID).Update("processing_error", bitstream.ExtractionError).Error
+	})
+	if dbErr != nil {
+		app.Logger.Error("db record extraction error failed", zap.Uint("bitstream", bitstream.ID), zap.Error(dbErr))
+	}
+}
+
+// markIngestFailed flags the item once its ingestion job has given up
+func (app *App) markIngestFailed(job *models.Job) {
+	if job.Kind != JobIngestBitstream || job.ItemID == nil {
+		return
+	}
+	err := app.DB.Model(&models.Item{}).Where("id = ?", *job.ItemID).
+		Updates(map[string]any{"processing": models.ProcessingFailed, "processing_error": job.LastError}).Error
+	if err != nil {
+		app.Logger.Error("db mark item failed", zap.Uint("item", *job.ItemID), zap.Error(err))
+	}
+}
+
+// ---------------- Tika ----------------
+
+// tikaHealthHandler probes the Tika server and reports the circuit breaker state
+func tikaHealthHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := app.Tika.Health(c.Request.Context())
+		status := http.StatusOK
+		if !h.OK {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, h)
+	}
+}
+
+// ---------------- Jobs ----------------
+
+// getJobHandler reports a job's state; non-admins only see jobs of items visible to them
+func getJobHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var job models.Job
+		if err := app.DB.First(&job, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+		access, err := resolveAccess(app, c)
+		if err != nil {
+			app.Logger.Error("resolve access failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+			return
+		}
+		if !access.isAdmin() {
+			var item models.Item
+			if job.ItemID == nil || app.DB.First(&item, *job.ItemID).Error != nil || !canViewItem(access, &item) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
@@ -0,0 +1,104 @@
+// internal/api/pagination.go
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// listParams is the page and ordering requested with limit, offset, sort and order
+type listParams struct {
+	Limit  int
+	Offset int
+	Sort   string // relevance, date or title
+	Desc   bool
+}
+
+// parseListParams reads the paging parameters; sorts lists the accepted sort keys,
+// the first being the default. Dates and relevance sort descending unless order=asc,
+// titles ascending unless order=desc.
+func parseListParams(c *gin.Context, sorts ...string) (listParams, error) {
+	p := listParams{Limit: defaultPageSize, Sort: sorts[0]}
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return p, fmt.Errorf("invalid limit")
+		}
+		p.Limit = min(n, maxPageSize)
+	}
+	if s := c.Query("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid offset")
+		}
+		p.Offset = n
+	}
+	if s := c.Query("sort"); s != "" {
+		valid := false
+		for _, key := range sorts {
+			valid = valid || key == s
+		}
+		if !valid {
+			return p, fmt.Errorf("invalid sort, expected one of %s", strings.Join(sorts, ", "))
+		}
+		p.Sort = s
+	}
+	p.Desc = p.Sort != "title"
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		p.Desc = false
+	case "desc":
+		p.Desc = true
+	default:
+		return p, fmt.Errorf("invalid order, expected asc or desc")
+	}
+	return p, nil
+}
+
+// orderBy renders the sort as an SQL ORDER BY using the column each sort key maps to,
+// with id as a tie breaker so pages are stable
+func (p listParams) orderBy(columns map[string]string) string {
+	dir := "asc"
+	if p.Desc {
+		dir = "desc"
+	}
+	return fmt.Sprintf("%s %s, id %s", columns[p.Sort], dir, dir)
+}
+
+// setPageHeaders reports the total in X-Total-Count and links to the neighbouring
+// pages in an RFC 8288 Link header
+func setPageHeaders(c *gin.Context, p listParams, total int64) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	link := func(offset int, rel string) string {
+		u := *c.Request.URL
+		q := u.Query()
+		q.Set("limit", strconv.Itoa(p.Limit))
+		q.Set("offset", strconv.Itoa(offset))
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+	}
+
+	links := []string{link(0, "first")}
+	if p.Offset > 0 {
+		links = append(links, link(max(p.Offset-p.Limit, 0), "prev"))
+	}
+	if int64(p.Offset+p.Limit) < total {
+		links = append(links, link(p.Offset+p.Limit, "next"))
+	}
+	last := 0
+	if total > 0 {
+		last = int((total - 1) / int64(p.Limit) * int64(p.Limit))
+	}
+	links = append(links, link(last, "last"))
+	c.Header("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,206 @@
+// internal/api/permissions.go
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/workflow"
+)
+
+const userKey = "auth.user"
+
+// actionRank orders actions so that a stronger grant implies the weaker ones
+var actionRank = map[string]int{
+	models.ActionRead:   1,
+	models.ActionSubmit: 2,
+	models.ActionReview: 3,
+	models.ActionAdmin:  4,
+}
+
+// impliedBy returns every action whose grant satisfies the requested one
+func impliedBy(action string) []string {
+	var actions []string
+	for a, rank := range actionRank {
+		if rank >= actionRank[action] {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// currentUser loads the authenticated user (with groups) once per request
+func currentUser(app *App, c *gin.Context) (*models.User, error) {
+	if v, ok := c.Get(userKey); ok {
+		return v.(*models.User), nil
+	}
+	claims := currentClaims(c)
+	if claims == nil {
+		return nil, nil
+	}
+	var user models.User
+	if err := app.DB.Preload("Groups").First(&user, claims.UserID).Error; err != nil {
+		return nil, err
+	}
+	c.Set(userKey, &user)
+	return &user, nil
+}
+
+// requireUser writes a 401 and returns false when no user is attached to the request
+func requireUser(app *App, c *gin.Context) (*models.User, bool) {
+	user, err := currentUser(app, c)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return nil, false
+	}
+	return user, true
+}
+
+func groupIDs(user *models.User) []uint {
+	ids := make([]uint, 0, len(user.Groups))
+	for _, g := range user.Groups {
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
+
+// principalScope matches policies granted to the user directly or to one of their groups
+func principalScope(user *models.User) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if gids := groupIDs(user); len(gids) > 0 {
+			return db.Where("user_id = ? OR group_id IN ?", user.ID, gids)
+		}
+		return db.Where("user_id = ?", user.ID)
+	}
+}
+
+// canCommunity reports whether user may perform action on the community
+func (app *App) canCommunity(user *models.User, communityID uint, action string) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+	if user.IsAdmin {
+		return true, nil
+	}
+	var count int64
+	err := app.DB.Model(&models.ResourcePolicy{}).
+		Where("resource_type = ? AND resource_id = ?", models.ResourceCommunity, communityID).
+		Where("action IN ?", impliedBy(action)).
+		Scopes(principalScope(user)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// canCollection reports whether user may perform action on the collection,
+// either through a policy on the collection itself or on its parent community
+func (app *App) canCollection(user *models.User, collectionID uint, action string) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+	if user.IsAdmin {
+		return true, nil
+	}
+	var col models.Collection
+	if err := app.DB.First(&col, collectionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	var count int64
+	err := app.DB.Model(&models.ResourcePolicy{}).
+		Where("(resource_type = ? AND resource_id = ?) OR (resource_type = ? AND resource_id = ?)",
+			models.ResourceCollection, col.ID, models.ResourceCommunity, col.CommunityID).
+		Where("action IN ?", impliedBy(action)).
+		Scopes(principalScope(user)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// authorizeCollection writes 401/403/500 and returns false unless the current user may act on the collection
+func authorizeCollection(app *App, c *gin.Context, collectionID uint, action string) bool {
+	user, ok := requireUser(app, c)
+	if !ok {
+		return false
+	}
+	allowed, err := app.canCollection(user, collectionID, action)
+	if err != nil {
+		app.Logger.Error("permission check failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions on collection"})
+		return false
+	}
+	return true
+}
+
+// authorizeItemFiles lets the item's submitter (while they may still submit to the
+// collection) and collection admins change its files, and only while it is editable
+func authorizeItemFiles(app *App, c *gin.Context, item *models.Item) bool {
+	user, ok := requireUser(app, c)
+	if !ok {
+		return false
+	}
+	isAdmin, err := app.canCollection(user, item.CollectionID, models.ActionAdmin)
+	if err != nil {
+		app.Logger.Error("permission check failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+		return false
+	}
+	if !isAdmin {
+		if item.SubmitterID != user.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only the submitter or a collection admin may change this item's files"})
+			return false
+		}
+		if !authorizeCollection(app, c, item.CollectionID, models.ActionSubmit) {
+			return false
+		}
+	}
+	if !workflow.Editable(item.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "files can only be changed while item is DRAFT or CHANGES_REQUESTED"})
+		return false
+	}
+	return true
+}
+
+// authorizeCommunity is the community-scoped counterpart of authorizeCollection
+func authorizeCommunity(app *App, c *gin.Context, communityID uint, action string) bool {
+	user, ok := requireUser(app, c)
+	if !ok {
+		return false
+	}
+	allowed, err := app.canCommunity(user, communityID, action)
+	if err != nil {
+		app.Logger.Error("permission check failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions on community"})
+		return false
+	}
+	return true
+}
+
+// adminOnly aborts unless the current user is a site administrator
+func adminOnly(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := requireUser(app, c)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if !user.IsAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -3,11 +3,11 @@ package api
 
 import (
 	"bytes"
+	"expvar"
 	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -15,17 +15,23 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/mohan2020coder/mSpace/internal/config"
-	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/embed"
+	"github.com/mohan2020coder/mSpace/internal/extract"
+	"github.com/mohan2020coder/mSpace/internal/jobs"
 	"github.com/mohan2020coder/mSpace/internal/search"
 	"github.com/mohan2020coder/mSpace/internal/storage"
 	"gorm.io/gorm"
 )
 
 type App struct {
-	Cfg    *config.Config
-	DB     *gorm.DB
-	Minio  *storage.MinioClient
-	Logger *zap.Logger
+	Cfg        *config.Config
+	DB         *gorm.DB
+	Storage    storage.Backend
+	Jobs       *jobs.Queue
+	Extractors *extract.Registry
+	Tika       *extract.Tika
+	Embedder   embed.Provider // nil when semantic search is disabled
+	Logger     *zap.Logger
 }
 
 func SetupRouter(app *App, searchIndex *search.SearchIndex) *gin.Engine {
@@ -37,9 +43,9 @@ func SetupRouter(app *App, searchIndex *search.SearchIndex) *gin.Engine {
 	// ----------------- CORS -----------------
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // allow all for now, can restrict domains
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Range", "Content-Disposition", "ETag", "Link", "X-Total-Count"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -136,51 +142,99 @@ func SetupRouter(app *App, searchIndex *search.SearchIndex) *gin.Engine {
 		log.Printf("[INFO] Response sent in %v\n", duration)
 	})
 
+	r.GET("/health/tika", tikaHealthHandler(app))
+
+	// Auth
+	authGroup := r.Group("/api/auth")
+	authGroup.POST("/login", loginHandler(app))
+	authGroup.POST("/refresh", authRequired(app), refreshHandler(app))
+	authGroup.GET("/me", authRequired(app), meHandler(app))
+
+	// Users and groups (site admins only)
+	users := r.Group("/api/users", authRequired(app), adminOnly(app))
+	users.GET("", listUsersHandler(app))
+	users.POST("", createUserHandler(app))
+
+	groups := r.Group("/api/groups", authRequired(app), adminOnly(app))
+	groups.GET("", listGroupsHandler(app))
+	groups.POST("", createGroupHandler(app))
+	groups.POST("/:id/members", addGroupMemberHandler(app))
+	groups.DELETE("/:id/members/:user_id", removeGroupMemberHandler(app))
+
+	// Fixity reports (site admins only)
+	fixity := r.Group("/api/admin/fixity", authRequired(app), adminOnly(app))
+	fixity.GET("", fixityReportHandler(app))
+	fixity.POST("/run", runFixityHandler(app))
+	fixity.POST("/bitstreams/:id", checkBitstreamFixityHandler(app))
+
+	// Search index maintenance (site admins only)
+	admin := r.Group("/api/admin", authRequired(app), adminOnly(app))
+	admin.POST("/reindex", reindexHandler(app))
+	admin.GET("/index/drift", indexDriftHandler(app, searchIndex))
+	admin.POST("/index/reconcile", indexDriftHandler(app, searchIndex))
+	admin.POST("/embed", embedAllHandler(app))
+	// expvar metrics (fixity counters, memstats, cmdline); admins only
+	admin.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+
+	// Resource policies (ADMIN on the community/collection)
+	policies := r.Group("/api/policies", authRequired(app))
+	policies.GET("", listPoliciesHandler(app))
+	policies.POST("", createPolicyHandler(app))
+	policies.DELETE("/:id", deletePolicyHandler(app))
+
 	// Communities
-	r.GET("/api/communities", listCommunitiesHandler(app))
-	r.POST("/api/communities", createCommunityHandler(app))
+	communities := r.Group("/api/communities", authRequired(app))
+	communities.GET("", listCommunitiesHandler(app))
+	communities.POST("", adminOnly(app), createCommunityHandler(app))
 
 	// Collections
-	r.GET("/api/collections", listCollectionsHandler(app))
-	r.POST("/api/collections", createCollectionHandler(app))
-
-	r.GET("/api/search", func(c *gin.Context) {
-		q := c.Query("q")
-		if q == "" {
-			c.JSON(400, gin.H{"error": "query required"})
-			return
-		}
-
-		collectionID := uint(0)
-		if cid := c.Query("collection_id"); cid != "" {
-			parsed, _ := strconv.Atoi(cid)
-			collectionID = uint(parsed)
-		}
-
-		author := c.Query("author")
+	collections := r.Group("/api/collections", authRequired(app))
+	collections.GET("", listCollectionsHandler(app))
+	collections.POST("", createCollectionHandler(app))
+	collections.GET("/:id/workflow", getWorkflowStepsHandler(app))
+	collections.PUT("/:id/workflow", putWorkflowStepsHandler(app))
 
-		ids, err := searchIndex.Search(q, collectionID, author)
-		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-
-		var items []models.Item
-		if len(ids) > 0 {
-			app.DB.Where("id IN ?", ids).Find(&items)
-		}
-
-		c.JSON(200, items)
-	})
+	r.GET("/api/search", optionalAuth(app), searchHandler(app, searchIndex))
 
 	// Items
+	// Reads are open to anonymous callers but filtered by visibility
 	items := r.Group("/api/items")
-	items.POST("", createItemHandler(app))
-	items.GET("", listItemsHandler(app))
-	items.GET("/:id", getItemHandler(app))
-	items.POST("/:id/file", uploadFileHandler(app, searchIndex))
-	items.POST("/:id/publish", publishItemHandler(app))
-	items.POST("/:id/reject", rejectItemHandler(app))
+	items.GET("", optionalAuth(app), listItemsHandler(app))
+	items.GET("/:id", optionalAuth(app), getItemHandler(app))
+	items.POST("", authRequired(app), createItemHandler(app))
+	items.PATCH("/:id", authRequired(app), updateItemHandler(app))
+	items.DELETE("/:id", authRequired(app), deleteItemHandler(app))
+	items.GET("/:id/history", optionalAuth(app), itemHistoryHandler(app))
+	items.POST("/:id/file", authRequired(app), uploadFileHandler(app))
+	items.GET("/:id/file", optionalAuth(app), currentFileHandler(app))
+	items.POST("/:id/uploads", authRequired(app), initiateUploadHandler(app))
+	items.GET("/:id/bundles", optionalAuth(app), listBundlesHandler(app))
+	items.GET("/:id/bundles/:bundle/:seq/file", optionalAuth(app), bundleFileHandler(app))
+	items.DELETE("/:id/bundles/:bundle/:seq", authRequired(app), removeBundleFileHandler(app))
+	items.GET("/:id/bundles/:bundle/:seq/versions", optionalAuth(app), listVersionsHandler(app))
+	items.GET("/:id/bundles/:bundle/:seq/versions/:version/file", optionalAuth(app), versionFileHandler(app))
+	items.POST("/:id/bundles/:bundle/:seq/versions/:version/restore", authRequired(app), restoreVersionHandler(app))
+	// The primary file, or ?bundle=&seq=
+	items.GET("/:id/versions", optionalAuth(app), listVersionsHandler(app))
+	items.GET("/:id/versions/:version/file", optionalAuth(app), versionFileHandler(app))
+	items.POST("/:id/versions/:version/restore", authRequired(app), restoreVersionHandler(app))
+	// Workflow transitions
+	items.POST("/:id/submit", authRequired(app), submitItemHandler(app))
+	items.POST("/:id/review", authRequired(app), startReviewHandler(app))
+	items.POST("/:id/publish", authRequired(app), publishItemHandler(app))
+	items.POST("/:id/request-changes", authRequired(app), requestChangesHandler(app))
+	items.POST("/:id/reject", authRequired(app), rejectItemHandler(app))
+	items.POST("/:id/withdraw", authRequired(app), withdrawItemHandler(app))
+
+	// Background jobs
+	r.GET("/api/jobs/:id", authRequired(app), getJobHandler(app))
+
+	// Resumable uploads
+	uploads := r.Group("/api/uploads", authRequired(app))
+	uploads.GET("/:upload_id", uploadStatusHandler(app))
+	uploads.PUT("/:upload_id/parts/:number", uploadPartHandler(app))
+	uploads.POST("/:upload_id/complete", completeUploadHandler(app))
+	uploads.DELETE("/:upload_id", abortUploadHandler(app))
 
 	return r
 }
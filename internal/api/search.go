@@ -0,0 +1,350 @@
+// internal/api/search.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/search"
+)
+
+// searchResult is one /api/search hit: the item, its score, a highlighted HTML snippet,
+// the fields that matched and the pages of its files that matched. In semantic and
+// hybrid mode the score is the fused one and the ranks and similarity explain it.
+type searchResult struct {
+	Item          models.Item         `json:"item"`
+	Score         float64             `json:"score"`
+	Similarity    float64             `json:"similarity,omitempty"`    // cosine similarity of the closest chunk
+	LexicalRank   int                 `json:"lexical_rank,omitempty"`  // 1-based rank among Bleve hits
+	SemanticRank  int                 `json:"semantic_rank,omitempty"` // 1-based rank by similarity
+	Snippet       string              `json:"snippet,omitempty"`
+	Highlights    map[string][]string `json:"highlights,omitempty"`
+	MatchedFields []string            `json:"matched_fields,omitempty"`
+	Pages         []search.PageHit    `json:"pages,omitempty"`
+}
+
+// searchResponse is the /api/search body
+type searchResponse struct {
+	Mode    string                  `json:"mode"`
+	Total   uint64                  `json:"total"`
+	Results []searchResult          `json:"results"`
+	Facets  map[string]search.Facet `json:"facets,omitempty"`
+}
+
+// parseSearchQuery reads q (in the advanced syntax with syntax=advanced), the filters collection_id, author, visibility, status,
+// from and to (YYYY-MM-DD or RFC 3339), the facet drill-downs year, petitioner,
+// respondent and case_number_prefix, and the facets to count (comma separated,
+// all by default) from the query string
+func parseSearchQuery(c *gin.Context) (search.Query, error) {
+	q := search.Query{
+		Text:             c.Query("q"),
+		Author:           c.Query("author"),
+		Visibility:       strings.ToUpper(c.Query("visibility")),
+		Status:           strings.ToUpper(c.Query("status")),
+		Petitioner:       c.Query("petitioner"),
+		Respondent:       c.Query("respondent"),
+		CaseNumberPrefix: c.Query("case_number_prefix"),
+	}
+	switch c.Query("syntax") {
+	case "", "simple":
+	case "advanced":
+		q.Advanced = true
+	default:
+		return q, fmt.Errorf("invalid syntax, expected simple or advanced")
+	}
+	if cid := c.Query("collection_id"); cid != "" {
+		parsed, err := strconv.ParseUint(cid, 10, 64)
+		if err != nil {
+			return q, fmt.Errorf("invalid collection_id")
+		}
+		q.CollectionID = uint(parsed)
+	}
+	if y := c.Query("year"); y != "" {
+		year, err := strconv.Atoi(y)
+		if err != nil || year <= 0 {
+			return q, fmt.Errorf("invalid year")
+		}
+		q.Year = year
+	}
+
+	facets, ok := c.GetQuery("facets")
+	switch {
+	case !ok:
+		for name := range search.FacetFields {
+			q.Facets = append(q.Facets, name)
+		}
+	case facets != "":
+		for _, name := range strings.Split(facets, ",") {
+			name = strings.TrimSpace(name)
+			if _, known := search.FacetFields[name]; !known {
+				return q, fmt.Errorf("unknown facet %q", name)
+			}
+			q.Facets = append(q.Facets, name)
+		}
+	}
+	if s := c.Query("facet_size"); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil || size <= 0 {
+			return q, fmt.Errorf("invalid facet_size")
+		}
+		q.FacetSize = size
+	}
+
+	var err error
+	if q.From, err = parseSearchDate(c.Query("from"), false); err != nil {
+		return q, fmt.Errorf("invalid from date")
+	}
+	if q.To, err = parseSearchDate(c.Query("to"), true); err != nil {
+		return q, fmt.Errorf("invalid to date")
+	}
+	return q, nil
+}
+
+// parseSearchDate accepts a date or a timestamp; a bare date used as the end of a
+// range covers that whole day
+func parseSearchDate(s string, endOfDay bool) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
+
+// searchHandler runs a full text search and returns one page of the hits visible to
+// the caller, best first unless another sort is requested. mode=semantic ranks items
+// by embedding similarity and mode=hybrid fuses both rankings.
+func searchHandler(app *App, searchIndex *search.SearchIndex) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("q") == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "query required"})
+			return
+		}
+		q, err := parseSearchQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		page, err := parseListParams(c, "relevance", "date", "title")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		q.Size, q.Offset, q.Sort, q.Desc = page.Limit, page.Offset, page.Sort, page.Desc
+
+		// Checked before the mode: semantic search never builds the index query
+		if q.Advanced {
+			if _, err := search.ParseAdvanced(q.Text); err != nil {
+				if !syntaxError(c, err) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				}
+				return
+			}
+		}
+
+		mode := c.DefaultQuery("mode", searchLexical)
+		switch mode {
+		case searchLexical:
+		case searchSemantic, searchHybrid:
+			if app.Embedder == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "semantic search is not enabled"})
+				return
+			}
+			if page.Sort != "relevance" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "sort is only supported in lexical mode"})
+				return
+			}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mode, expected lexical, semantic or hybrid"})
+			return
+		}
+
+		access, err := resolveAccess(app, c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+			return
+		}
+
+		if mode != searchLexical {
+			rankedSearch(app, c, searchIndex, q, page, access, mode)
+			return
+		}
+
+		res, ok := runSearch(app, c, searchIndex, q, access)
+		if !ok {
+			return
+		}
+		ids := make([]uint, len(res.Hits))
+		for i, h := range res.Hits {
+			ids[i] = h.ID
+		}
+		byID := hitItems(app, access, ids)
+
+		// Keep the index's ranking
+		results := []searchResult{}
+		for _, h := range res.Hits {
+			if it, ok := byID[h.ID]; ok {
+				results = append(results, lexicalResult(it, h))
+			}
+		}
+		setPageHeaders(c, page, int64(res.Total))
+		c.JSON(http.StatusOK, searchResponse{Mode: mode, Total: res.Total, Results: results, Facets: res.Facets})
+	}
+}
+
+// rankedSearch answers semantic and hybrid queries. Each ranking contributes its top
+// embedding.candidates items, so results can be paged through the fused list only;
+// facets count the lexical matches.
+func rankedSearch(app *App, c *gin.Context, searchIndex *search.SearchIndex, q search.Query, page listParams,
+	access itemAccess, mode string) {
+	depth := app.Cfg.Embedding.Candidates
+
+	var lexical *search.Result
+	var lexicalIDs []uint
+	lexicalHits := map[uint]search.Hit{}
+	if mode == searchHybrid {
+		lq := q
+		lq.Size, lq.Offset = depth, 0
+		var ok bool
+		if lexical, ok = runSearch(app, c, searchIndex, lq, access); !ok {
+			return
+		}
+		for _, h := range lexical.Hits {
+			lexicalIDs = append(lexicalIDs, h.ID)
+			lexicalHits[h.ID] = h
+		}
+	}
+
+	// Filters other than access live in the index, so semantic hits are checked against it
+	var allowed map[uint]bool
+	if q.Filtered() {
+		var err error
+		if allowed, err = searchIndex.Matching(q, access.searchAccess()); err != nil {
+			app.Logger.Error("bleve filter failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	semantic, err := app.semanticSearch(c.Request.Context(), q.Text, access, allowed, depth)
+	if err != nil {
+		app.Logger.Error("semantic search failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "semantic search failed"})
+		return
+	}
+	semanticIDs := make([]uint, len(semantic))
+	semanticHits := make(map[uint]semanticHit, len(semantic))
+	for i, h := range semantic {
+		semanticIDs[i] = h.ItemID
+		semanticHits[h.ItemID] = h
+	}
+
+	fused := fuseRanks(lexicalIDs, semanticIDs)
+	window := fused[min(page.Offset, len(fused)):min(page.Offset+page.Limit, len(fused))]
+	ids := make([]uint, len(window))
+	var chunkIDs []uint
+	for i, f := range window {
+		ids[i] = f.ItemID
+		if _, ok := lexicalHits[f.ItemID]; !ok {
+			chunkIDs = append(chunkIDs, semanticHits[f.ItemID].ChunkID)
+		}
+	}
+	byID := hitItems(app, access, ids)
+	excerpts := app.chunkExcerpts(chunkIDs)
+
+	results := []searchResult{}
+	for _, f := range window {
+		it, ok := byID[f.ItemID]
+		if !ok {
+			continue
+		}
+		r := searchResult{Item: it}
+		if h, ok := lexicalHits[f.ItemID]; ok {
+			r = lexicalResult(it, h)
+		}
+		r.Score, r.LexicalRank, r.SemanticRank = f.Score, f.LexicalRank, f.SemanticRank
+		if h, ok := semanticHits[f.ItemID]; ok {
+			r.Similarity = h.Similarity
+			if mode == searchSemantic {
+				r.Score = h.Similarity
+			}
+			if r.Snippet == "" {
+				r.Snippet = excerpts[h.ChunkID]
+			}
+			if len(r.Pages) == 0 {
+				r.Pages = h.Pages
+			}
+		}
+		results = append(results, r)
+	}
+
+	resp := searchResponse{Mode: mode, Total: uint64(len(fused)), Results: results}
+	if lexical != nil {
+		resp.Facets = lexical.Facets
+	}
+	setPageHeaders(c, page, int64(len(fused)))
+	c.JSON(http.StatusOK, resp)
+}
+
+// runSearch queries the index, answering syntax errors with 400 and failures with 500
+func runSearch(app *App, c *gin.Context, searchIndex *search.SearchIndex, q search.Query, access itemAccess) (*search.Result, bool) {
+	res, err := searchIndex.Search(q, access.searchAccess())
+	if syntaxError(c, err) {
+		return nil, false
+	}
+	if err != nil {
+		app.Logger.Error("bleve search failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return res, true
+}
+
+// syntaxError answers an advanced query syntax error with 400 and reports whether err was one
+func syntaxError(c *gin.Context, err error) bool {
+	var syntaxErr *search.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": syntaxErr.Msg, "position": syntaxErr.Pos})
+	return true
+}
+
+// hitItems reloads the hit items that are still visible to the caller
+func hitItems(app *App, access itemAccess, ids []uint) map[uint]models.Item {
+	var items []models.Item
+	if len(ids) > 0 {
+		app.DB.Scopes(visibleItems(access)).Where("id IN ?", ids).Find(&items)
+	}
+	byID := make(map[uint]models.Item, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+	return byID
+}
+
+func lexicalResult(item models.Item, h search.Hit) searchResult {
+	return searchResult{
+		Item:          item,
+		Score:         h.Score,
+		Snippet:       h.Snippet(),
+		Highlights:    h.Fragments,
+		MatchedFields: h.MatchedFields,
+		Pages:         h.Pages,
+	}
+}
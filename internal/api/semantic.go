@@ -0,0 +1,372 @@
+// internal/api/semantic.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/embed"
+	"github.com/mohan2020coder/mSpace/internal/jobs"
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/search"
+)
+
+// Embedding jobs
+const (
+	JobEmbedItem = "embed_item" // embed one item's chunks, or drop them once it is deleted
+	JobEmbedAll  = "embed_all"  // embed every item, e.g. after switching models
+)
+
+// Search modes
+const (
+	searchLexical  = "lexical"  // Bleve only
+	searchSemantic = "semantic" // chunk embeddings only
+	searchHybrid   = "hybrid"   // both, fused by reciprocal rank
+)
+
+// rrfK damps the weight of the top ranks in reciprocal rank fusion; 60 is the value
+// from the original paper and works without tuning
+const rrfK = 60
+
+// semanticPagesPerHit is the number of closest chunks whose pages are reported per item
+const semanticPagesPerHit = 3
+
+// queueEmbed schedules embedding of the item using tx; a no-op while semantic search is disabled
+func (app *App) queueEmbed(tx *gorm.DB, itemID uint) error {
+	if app.Embedder == nil {
+		return nil
+	}
+	_, err := app.Jobs.Enqueue(tx, JobEmbedItem, &itemID, indexPayload{ItemID: itemID})
+	return err
+}
+
+// itemChunks splits the item's metadata and the pages of its current original files
+// into the passages that get embedded; items without pages fall back to their full text
+func (app *App) itemChunks(item *models.Item) ([]models.ItemChunk, error) {
+	pages, err := app.itemPages([]uint{item.ID})
+	if err != nil {
+		return nil, err
+	}
+	cfg := app.Cfg.Embedding
+	var chunks []models.ItemChunk
+	next := map[[2]int]int{}
+	add := func(sequence, page int, text string) {
+		for _, c := range embed.Chunk(text, cfg.ChunkWords, cfg.ChunkOverlap) {
+			key := [2]int{sequence, page}
+			chunks = append(chunks, models.ItemChunk{
+				ItemID: item.ID, Sequence: sequence, Page: page, Chunk: next[key], Text: c, Model: app.Embedder.Model(),
+			})
+			next[key]++
+		}
+	}
+	add(0, 0, strings.Join([]string{item.Title, item.Author, item.Abstract}, "\n"))
+	for _, p := range pages[item.ID] {
+		add(p.Sequence, p.Page, p.Text)
+	}
+	if len(pages[item.ID]) == 0 {
+		add(0, 0, item.FullText)
+	}
+	return chunks, nil
+}
+
+// embedItem replaces the item's chunks with fresh ones. Vectors of chunks whose text
+// is unchanged are reused, so re-embedding an edited item only calls the provider
+// for new passages.
+func (app *App) embedItem(ctx context.Context, item *models.Item) error {
+	chunks, err := app.itemChunks(item)
+	if err != nil {
+		return err
+	}
+
+	var existing []models.ItemChunk
+	err = app.DB.Select("text", "embedding").
+		Where("item_id = ? AND model = ?", item.ID, app.Embedder.Model()).Find(&existing).Error
+	if err != nil {
+		return err
+	}
+	known := make(map[string][]byte, len(existing))
+	for _, c := range existing {
+		known[c.Text] = c.Embedding
+	}
+	var todo []int
+	for i := range chunks {
+		if v, ok := known[chunks[i].Text]; ok {
+			chunks[i].Embedding = v
+		} else {
+			todo = append(todo, i)
+		}
+	}
+	for start := 0; start < len(todo); start += app.Cfg.Embedding.BatchSize {
+		batch := todo[start:min(start+app.Cfg.Embedding.BatchSize, len(todo))]
+		texts := make([]string, len(batch))
+		for j, i := range batch {
+			texts[j] = chunks[i].Text
+		}
+		vectors, err := app.Embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("embed item %d: %w", item.ID, err)
+		}
+		for j, i := range batch {
+			chunks[i].Embedding = embed.Encode(embed.Normalize(vectors[j]))
+		}
+	}
+
+	return app.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("item_id = ?", item.ID).Delete(&models.ItemChunk{}).Error; err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(chunks, 100).Error
+	})
+}
+
+// embedItemJob brings one item's chunks in line with the database
+func (app *App) embedItemJob() jobs.Handler {
+	return func(ctx context.Context, job *models.Job) error {
+		if app.Embedder == nil {
+			return nil
+		}
+		var payload indexPayload
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return jobs.Permanent(err)
+		}
+		var item models.Item
+		err := app.DB.First(&item, payload.ItemID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return app.DB.Where("item_id = ?", payload.ItemID).Delete(&models.ItemChunk{}).Error
+		}
+		if err != nil {
+			return err
+		}
+		return app.embedItem(ctx, &item)
+	}
+}
+
+// embedAllJob embeds every item and drops chunks of deleted items and of other models
+func (app *App) embedAllJob() jobs.Handler {
+	return func(ctx context.Context, job *models.Job) error {
+		if app.Embedder == nil {
+			return nil
+		}
+		embedded := 0
+		var batch []models.Item
+		err := app.DB.Order("id").FindInBatches(&batch, reindexBatchSize, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := app.embedItem(ctx, &batch[i]); err != nil {
+					return err
+				}
+				embedded++
+			}
+			return nil
+		}).Error
+		app.Logger.Info("embedding finished", zap.Int("items", embedded), zap.Error(err))
+		if err != nil {
+			return err
+		}
+		return app.DB.
+			Where("model <> ? OR item_id NOT IN (?)", app.Embedder.Model(), app.DB.Model(&models.Item{}).Select("id")).
+			Delete(&models.ItemChunk{}).Error
+	}
+}
+
+// embedAllHandler queues embedding of every item
+func embedAllHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if app.Embedder == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "semantic search is not enabled"})
+			return
+		}
+		job, err := app.Jobs.Enqueue(app.DB, JobEmbedAll, nil, struct{}{})
+		if err != nil {
+			app.Logger.Error("db enqueue embedding failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue embedding"})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "embedding queued",
+			"job_id":  job.ID,
+			"job_url": fmt.Sprintf("/api/jobs/%d", job.ID),
+		})
+	}
+}
+
+// ---------------- Semantic search ----------------
+
+// semanticHit is an item ranked by the similarity of its closest chunk to the query
+type semanticHit struct {
+	ItemID     uint
+	Similarity float64
+	ChunkID    uint             // the closest chunk
+	Pages      []search.PageHit // file pages of the closest chunks, best first
+}
+
+// semanticSearch embeds the text and ranks the items visible to the caller by their
+// closest chunk. Items missing from allowed are skipped unless allowed is nil. Every
+// chunk of the current model is compared, which is exact but linear in the corpus.
+func (app *App) semanticSearch(ctx context.Context, text string, access itemAccess, allowed map[uint]bool, limit int) ([]semanticHit, error) {
+	vectors, err := app.Embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	query := embed.Normalize(vectors[0])
+
+	rows, err := app.DB.WithContext(ctx).Table("item_chunks").
+		Select("item_chunks.id, item_chunks.item_id, item_chunks.sequence, item_chunks.page, item_chunks.embedding").
+		Joins("JOIN items ON items.id = item_chunks.item_id AND items.deleted_at IS NULL").
+		Where("item_chunks.model = ?", app.Embedder.Model()).
+		Scopes(visibleItems(access)).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type scored struct {
+		similarity float64
+		chunkID    uint
+		page       search.PageHit
+	}
+	closest := map[uint][]scored{}
+	for rows.Next() {
+		var chunkID, itemID uint
+		var sequence, page int
+		var vector []byte
+		if err := rows.Scan(&chunkID, &itemID, &sequence, &page, &vector); err != nil {
+			return nil, err
+		}
+		if allowed != nil && !allowed[itemID] {
+			continue
+		}
+		s := scored{embed.Dot(query, embed.Decode(vector)), chunkID, search.PageHit{Sequence: sequence, Page: page}}
+		top := closest[itemID]
+		i := sort.Search(len(top), func(i int) bool { return top[i].similarity < s.similarity })
+		if i < semanticPagesPerHit {
+			top = append(top[:i], append([]scored{s}, top[i:]...)...)
+			closest[itemID] = top[:min(len(top), semanticPagesPerHit)]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	hits := make([]semanticHit, 0, len(closest))
+	for itemID, top := range closest {
+		h := semanticHit{ItemID: itemID, Similarity: top[0].similarity, ChunkID: top[0].chunkID}
+		for _, s := range top {
+			if s.page.Page > 0 && !containsPage(h.Pages, s.page) {
+				h.Pages = append(h.Pages, s.page)
+			}
+		}
+		hits = append(hits, h)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Similarity != hits[j].Similarity {
+			return hits[i].Similarity > hits[j].Similarity
+		}
+		return hits[i].ItemID < hits[j].ItemID
+	})
+	return hits[:min(len(hits), limit)], nil
+}
+
+func containsPage(pages []search.PageHit, p search.PageHit) bool {
+	for _, q := range pages {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// fusedHit is an item's place in the combined ranking
+type fusedHit struct {
+	ItemID       uint
+	Score        float64
+	LexicalRank  int // 1-based, 0 when the item is not in that ranking
+	SemanticRank int
+}
+
+// fuseRanks merges rankings by reciprocal rank fusion: each item scores the sum of
+// 1/(rrfK + rank) over the rankings it appears in, so items ranked well by both
+// rise to the top without comparing Bleve scores with cosine similarities
+func fuseRanks(lexical, semantic []uint) []fusedHit {
+	byID := map[uint]*fusedHit{}
+	var order []uint
+	hit := func(id uint) *fusedHit {
+		h, ok := byID[id]
+		if !ok {
+			h = &fusedHit{ItemID: id}
+			byID[id] = h
+			order = append(order, id)
+		}
+		return h
+	}
+	for i, id := range lexical {
+		h := hit(id)
+		h.LexicalRank = i + 1
+		h.Score += 1 / float64(rrfK+i+1)
+	}
+	for i, id := range semantic {
+		h := hit(id)
+		h.SemanticRank = i + 1
+		h.Score += 1 / float64(rrfK+i+1)
+	}
+
+	fused := make([]fusedHit, len(order))
+	for i, id := range order {
+		fused[i] = *byID[id]
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		if fused[i].Score != fused[j].Score {
+			return fused[i].Score > fused[j].Score
+		}
+		return fused[i].ItemID < fused[j].ItemID
+	})
+	return fused
+}
+
+// chunkExcerpts loads the texts of the given chunks, shortened and HTML escaped to
+// match the highlighted snippets of lexical hits
+func (app *App) chunkExcerpts(chunkIDs []uint) map[uint]string {
+	out := map[uint]string{}
+	if len(chunkIDs) == 0 {
+		return out
+	}
+	var chunks []models.ItemChunk
+	if err := app.DB.Select("id", "text").Where("id IN ?", chunkIDs).Find(&chunks).Error; err != nil {
+		app.Logger.Error("db load chunks failed", zap.Error(err))
+		return out
+	}
+	for _, c := range chunks {
+		out[c.ID] = html.EscapeString(excerpt(c.Text, 300))
+	}
+	return out
+}
+
+// excerpt cuts text to at most n bytes at a word boundary
+func excerpt(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	cut := strings.LastIndexByte(text[:n], ' ')
+	if cut <= 0 {
+		for cut = n; cut > 0 && !utf8.RuneStart(text[cut]); cut-- {
+		}
+	}
+	return text[:cut] + "…"
+}
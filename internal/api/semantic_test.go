@@ -0,0 +1,137 @@
+// internal/api/semantic_test.go
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mohan2020coder/mSpace/internal/embed"
+)
+
+func fusedIDs(hits []fusedHit) []uint {
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ItemID
+	}
+	return ids
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFuseRanks(t *testing.T) {
+	// 2 and 3 appear in both rankings, so they beat the items only one ranking put
+	// first; those two tie and fall back to the item id
+	fused := fuseRanks([]uint{1, 2, 3}, []uint{4, 2, 3})
+	if got, want := fusedIDs(fused), []uint{2, 3, 1, 4}; !equalIDs(got, want) {
+		t.Fatalf("order %v, want %v", got, want)
+	}
+
+	ranks := map[uint][2]int{1: {1, 0}, 2: {2, 2}, 3: {3, 3}, 4: {0, 1}}
+	for _, h := range fused {
+		if r := ranks[h.ItemID]; h.LexicalRank != r[0] || h.SemanticRank != r[1] {
+			t.Errorf("item %d ranks %d/%d, want %d/%d", h.ItemID, h.LexicalRank, h.SemanticRank, r[0], r[1])
+		}
+	}
+	if want := 2.0 / (rrfK + 2); fused[0].Score != want {
+		t.Errorf("score %f, want %f", fused[0].Score, want)
+	}
+
+	// Equal scores fall back to the item id
+	if got, want := fusedIDs(fuseRanks([]uint{9}, []uint{5})), []uint{5, 9}; !equalIDs(got, want) {
+		t.Errorf("tie order %v, want %v", got, want)
+	}
+	if len(fuseRanks(nil, nil)) != 0 {
+		t.Errorf("empty rankings should fuse to nothing")
+	}
+}
+
+// TestHybridRanking ranks a small corpus semantically with the fake provider, the
+// way semanticSearch does, and fuses it with a lexical ranking
+func TestHybridRanking(t *testing.T) {
+	f := &embed.Fake{Dims: 256}
+	corpus := map[uint]string{
+		1: "bail granted to the accused in a murder case",
+		2: "land acquisition compensation enhanced on appeal",
+		3: "anticipatory bail refused in an economic offence",
+	}
+	query := "bail for the accused"
+
+	ids := []uint{1, 2, 3}
+	texts := make([]string, len(ids))
+	for i, id := range ids {
+		texts[i] = embed.Chunk(corpus[id], 200, 20)[0]
+	}
+	vectors, err := f.Embed(context.Background(), append(texts, query))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := vectors[len(ids)]
+	similarity := map[uint]float64{}
+	for i, id := range ids {
+		similarity[id] = embed.Dot(q, vectors[i])
+	}
+	semantic := append([]uint(nil), ids...)
+	sort.Slice(semantic, func(i, j int) bool { return similarity[semantic[i]] > similarity[semantic[j]] })
+	if !equalIDs(semantic, []uint{1, 3, 2}) {
+		t.Fatalf("semantic order %v, want [1 3 2] (similarities %v)", semantic, similarity)
+	}
+
+	// The lexical ranking missed item 3 and preferred 2; 1 leads on both counts,
+	// and 3 is still found through its meaning
+	fused := fuseRanks([]uint{2, 1}, semantic)
+	if got, want := fusedIDs(fused), []uint{1, 2, 3}; !equalIDs(got, want) {
+		t.Fatalf("hybrid order %v, want %v", got, want)
+	}
+	if fused[2].LexicalRank != 0 || fused[2].SemanticRank != 2 {
+		t.Errorf("item 3 ranks %d/%d, want 0/2", fused[2].LexicalRank, fused[2].SemanticRank)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	cases := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{"short text", 20, "short text"},
+		{"cut at a word boundary", 12, "cut at a…"},
+		{"unbrokenword", 5, "unbro…"},
+		{"ééé", 3, "é…"}, // never splits a rune
+	}
+	for _, tc := range cases {
+		if got := excerpt(tc.text, tc.n); got != tc.want {
+			t.Errorf("excerpt(%q, %d) = %q, want %q", tc.text, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestSemanticSearchRejectsBadAdvancedSyntax(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := &App{Embedder: &embed.Fake{Dims: 8}}
+	for _, mode := range []string{searchLexical, searchSemantic, searchHybrid} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/search?syntax=advanced&mode="+mode+"&q="+url.QueryEscape(`title:"writ`), nil)
+		searchHandler(app, nil)(c)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unterminated phrase") {
+			t.Errorf("mode %s: got %d %s, want 400 for the syntax error", mode, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+// internal/api/uploads.go
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/storage"
+)
+
+// maxUploadParts is the S3 limit on parts per multipart upload
+const maxUploadParts = 10000
+
+type initiateUploadReq struct {
+	FileName string `json:"file_name" binding:"required"`
+	Size     int64  `json:"size" binding:"required,gt=0"`
+	MimeType string `json:"mime_type"`
+	Bundle   string `json:"bundle"`
+	Sequence string `json:"sequence"`
+	Append   bool   `json:"append"`
+}
+
+// partCount is the number of parts a session is split into
+func partCount(s *models.UploadSession) int {
+	return int((s.Size + s.PartSize - 1) / s.PartSize)
+}
+
+// loadUploadSession fetches the session named by :upload_id; only its uploader and site admins see it
+func loadUploadSession(app *App, c *gin.Context) (*models.UploadSession, bool) {
+	user, ok := requireUser(app, c)
+	if !ok {
+		return nil, false
+	}
+	var s models.UploadSession
+	if err := app.DB.First(&s, c.Param("upload_id")).Error; err != nil || (s.UploaderID != user.ID && !user.IsAdmin) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		return nil, false
+	}
+	return &s, true
+}
+
+// requireActiveUpload answers 409 for sessions that were completed, aborted or expired
+func requireActiveUpload(c *gin.Context, s *models.UploadSession) bool {
+	if s.Status != models.UploadActive || time.Now().After(s.ExpiresAt) {
+		c.JSON(http.StatusConflict, gin.H{"error": "upload is no longer active"})
+		return false
+	}
+	return true
+}
+
+func (app *App) uploadParts(sessionID uint) ([]models.UploadPart, error) {
+	var parts []models.UploadPart
+	err := app.DB.Where("session_id = ?", sessionID).Order("number").Find(&parts).Error
+	return parts, err
+}
+
+// missingParts lists the part numbers not received yet
+func missingParts(s *models.UploadSession, parts []models.UploadPart) []int {
+	have := map[int]bool{}
+	for _, p := range parts {
+		have[p.Number] = true
+	}
+	missing := []int{}
+	for n := 1; n <= partCount(s); n++ {
+		if !have[n] {
+			missing = append(missing, n)
+		}
+	}
+	return missing
+}
+
+// uploadProgress reports received bytes and the parts still missing
+func uploadProgress(s *models.UploadSession, parts []models.UploadPart) gin.H {
+	var received int64
+	for _, p := range parts {
+		received += p.Size
+	}
+	return gin.H{
+		"upload_id":     s.ID,
+		"item_id":       s.ItemID,
+		"status":        s.Status,
+		"file_name":     s.FileName,
+		"size":          s.Size,
+		"received":      received,
+		"percent":       float64(received) * 100 / float64(s.Size),
+		"part_size":     s.PartSize,
+		"part_count":    partCount(s),
+		"parts":         parts,
+		"missing_parts": missingParts(s, parts),
+		"expires_at":    s.ExpiresAt,
+	}
+}
+
+// ---------------- Uploads ----------------
+
+// initiateUploadHandler opens a resumable upload for one file of the item
+func initiateUploadHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		if !authorizeItemFiles(app, c, item) {
+			return
+		}
+		var req initiateUploadReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		bundle := strings.ToUpper(req.Bundle)
+		if bundle == "" {
+			bundle = models.BundleOriginal
+		}
+		if !validBundle(bundle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bundle must be one of ORIGINAL, TEXT, THUMBNAIL, LICENSE"})
+			return
+		}
+		sequence, err := app.targetSequence(item.ID, bundle, req.Sequence, req.Append)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		partSize := app.Cfg.Uploads.PartSize
+		if (req.Size+partSize-1)/partSize > maxUploadParts {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file too large"})
+			return
+		}
+		mimeType := req.MimeType
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		version, err := app.nextBitstreamVersion(item.ID, bundle, sequence)
+		if err != nil {
+			app.Logger.Error("db version lookup failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start upload"})
+			return
+		}
+
+		// The version in the object name is provisional; the bitstream gets the next free one on completion
+		objectName := bitstreamObjectName(item.ID, bundle, sequence, version, req.FileName)
+		uploadID, err := storage.MultipartFor(app.Storage).NewMultipartUpload(c.Request.Context(), objectName, mimeType)
+		if err != nil {
+			app.Logger.Error("storage multipart init failed", zap.Error(err))
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to start upload"})
+			return
+		}
+
+		actor, _ := currentUser(app, c)
+		session := models.UploadSession{
+			ItemID:          item.ID,
+			Bundle:          bundle,
+			Sequence:        sequence,
+			ObjectName:      objectName,
+			StorageUploadID: uploadID,
+			FileName:        req.FileName,
+			MimeType:        mimeType,
+			Size:            req.Size,
+			PartSize:        partSize,
+			UploaderID:      actor.ID,
+			Status:          models.UploadActive,
+			ExpiresAt:       time.Now().Add(app.Cfg.Uploads.SessionTTL),
+		}
+		if err := app.DB.Create(&session).Error; err != nil {
+			app.Logger.Error("db create upload failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start upload"})
+			return
+		}
+		c.JSON(http.StatusCreated, uploadProgress(&session, nil))
+	}
+}
+
+// uploadPartHandler stores the request body as part :number; parts may arrive in any
+// order and a part sent again replaces the earlier copy
+func uploadPartHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, ok := loadUploadSession(app, c)
+		if !ok || !requireActiveUpload(c, session) {
+			return
+		}
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil || number < 1 || number > partCount(session) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "part number must be between 1 and " + strconv.Itoa(partCount(session))})
+			return
+		}
+		expected := session.PartSize
+		if number == partCount(session) {
+			expected = session.Size - int64(number-1)*session.PartSize
+		}
+		if c.Request.ContentLength != expected {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "part " + strconv.Itoa(number) + " must be " + strconv.FormatInt(expected, 10) + " bytes"})
+			return
+		}
+
+		part, err := storage.MultipartFor(app.Storage).PutPart(c.Request.Context(), session.ObjectName, session.StorageUploadID, number, c.Request.Body, expected)
+		if err != nil {
+			app.Logger.Error("storage put part failed", zap.Uint("upload", session.ID), zap.Int("part", number), zap.Error(err))
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to store part"})
+			return
+		}
+
+		err = app.DB.Transaction(func(tx *gorm.DB) error {
+			row := models.UploadPart{SessionID: session.ID, Number: number, ETag: part.ETag, Size: part.Size}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "session_id"}, {Name: "number"}},
+				DoUpdates: clause.AssignmentColumns([]string{"e_tag", "size", "created_at"}),
+			}).Create(&row).Error
+			if err != nil {
+				return err
+			}
+			return tx.Model(session).Update("expires_at", time.Now().Add(app.Cfg.Uploads.SessionTTL)).Error
+		})
+		if err != nil {
+			app.Logger.Error("db save part failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record part"})
+			return
+		}
+
+		parts, err := app.uploadParts(session.ID)
+		if err != nil {
+			app.Logger.Error("db list parts failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load upload"})
+			return
+		}
+		c.JSON(http.StatusOK, uploadProgress(session, parts))
+	}
+}
+
+func uploadStatusHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, ok := loadUploadSession(app, c)
+		if !ok {
+			return
+		}
+		parts, err := app.uploadParts(session.ID)
+		if err != nil {
+			app.Logger.Error("db list parts failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load upload"})
+			return
+		}
+		c.JSON(http.StatusOK, uploadProgress(session, parts))
+	}
+}
+
+var errUploadIncomplete = errors.New("upload is missing parts")
+
+// completeUploadHandler assembles the parts and attaches the result to the item like a direct upload
+func completeUploadHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, ok := loadUploadSession(app, c)
+		if !ok || !requireActiveUpload(c, session) {
+			return
+		}
+		var item models.Item
+		if err := app.DB.First(&item, session.ItemID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+		if !authorizeItemFiles(app, c, &item) {
+			return
+		}
+
+		parts, err := app.uploadParts(session.ID)
+		if err != nil {
+			app.Logger.Error("db list parts failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load upload"})
+			return
+		}
+		if len(missingParts(session, parts)) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": errUploadIncomplete.Error(), "progress": uploadProgress(session, parts)})
+			return
+		}
+
+		// Claim the session so a repeated complete cannot attach the file twice
+		res := app.DB.Model(session).Where("status = ?", models.UploadActive).Update("status", models.UploadCompleted)
+		if res.Error != nil || res.RowsAffected == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "upload is no longer active"})
+			return
+		}
+		release := func() {
+			app.DB.Model(session).Update("status", models.UploadActive)
+		}
+
+		ctx := context.Background()
+		storageParts := make([]storage.Part, len(parts))
+		for i, p := range parts {
+			storageParts[i] = storage.Part{Number: p.Number, ETag: p.ETag, Size: p.Size}
+		}
+		if err := storage.MultipartFor(app.Storage).CompleteMultipartUpload(ctx, session.ObjectName, session.StorageUploadID, storageParts); err != nil {
+			release()
+			app.Logger.Error("storage multipart complete failed", zap.Uint("upload", session.ID), zap.Error(err))
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to assemble upload"})
+			return
+		}
+		// The storage upload id and its parts are consumed now, so a retry could never
+		// assemble them again: later failures end the session instead of releasing it
+		fail := func() {
+			if err := app.Storage.Delete(ctx, session.ObjectName); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				app.Logger.Warn("storage delete of failed upload failed", zap.Uint("upload", session.ID), zap.Error(err))
+			}
+			app.DB.Where("session_id = ?", session.ID).Delete(&models.UploadPart{})
+			app.DB.Model(session).Update("status", models.UploadFailed)
+		}
+
+		// Parts are hashed by storage only individually, so checksums come from reading the object back
+		obj, info, err := app.Storage.Get(ctx, session.ObjectName)
+		if err != nil {
+			fail()
+			app.Logger.Error("storage read back failed", zap.Error(err))
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to read assembled upload"})
+			return
+		}
+		sums, size, err := storage.ComputeDigests(obj)
+		obj.Close()
+		if err != nil || size != session.Size {
+			fail()
+			app.Logger.Error("upload verification failed", zap.Int64("size", size), zap.Int64("expected", session.Size), zap.Error(err))
+			c.JSON(http.StatusBadGateway, gin.H{"error": "assembled upload does not match declared size"})
+			return
+		}
+
+		version, err := app.nextBitstreamVersion(item.ID, session.Bundle, session.Sequence)
+		if err != nil {
+			fail()
+			app.Logger.Error("db version lookup failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update item"})
+			return
+		}
+		bitstream := &models.Bitstream{
+			ItemID:       item.ID,
+			Bundle:       session.Bundle,
+			Sequence:     session.Sequence,
+			Version:      version,
+			ObjectName:   session.ObjectName,
+			FileName:     session.FileName,
+			Size:         info.Size,
+			Checksum:     sums.SHA256,
+			ChecksumMD5:  sums.MD5,
+			MimeType:     session.MimeType,
+			UploaderID:   session.UploaderID,
+			LegalJSON:    "{}",
+			FixityStatus: models.FixityUnchecked,
+		}
+		actor, _ := currentUser(app, c)
+		job, err := app.attachBitstream(&item, bitstream, actor)
+		if err != nil {
+			fail()
+			app.Logger.Error("db update failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update item"})
+			return
+		}
+
+		app.DB.Model(session).Update("bitstream_id", bitstream.ID)
+		app.DB.Where("session_id = ?", session.ID).Delete(&models.UploadPart{})
+		c.JSON(http.StatusAccepted, uploadResponse(bitstream, job))
+	}
+}
+
+func abortUploadHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, ok := loadUploadSession(app, c)
+		if !ok || !requireActiveUpload(c, session) {
+			return
+		}
+		if err := app.abortUpload(c.Request.Context(), session, models.UploadAborted); err != nil {
+			app.Logger.Error("abort upload failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to abort upload"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "upload aborted"})
+	}
+}
+
+// abortUpload discards stored parts and closes the session with the given status
+func (app *App) abortUpload(ctx context.Context, session *models.UploadSession, status string) error {
+	err := storage.MultipartFor(app.Storage).AbortMultipartUpload(ctx, session.ObjectName, session.StorageUploadID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return app.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", session.ID).Delete(&models.UploadPart{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(session).Update("status", status).Error
+	})
+}
+
+// RunUploadCleanup periodically aborts upload sessions that stopped receiving parts
+func (app *App) RunUploadCleanup(ctx context.Context) {
+	ticker := time.NewTicker(app.Cfg.Uploads.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		if err := app.expireUploads(ctx); err != nil {
+			app.Logger.Error("upload cleanup failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (app *App) expireUploads(ctx context.Context) error {
+	var sessions []models.UploadSession
+	if err := app.DB.Where("status = ? AND expires_at < ?", models.UploadActive, time.Now()).Find(&sessions).Error; err != nil {
+		return err
+	}
+	for i := range sessions {
+		if err := app.abortUpload(ctx, &sessions[i], models.UploadExpired); err != nil {
+			app.Logger.Warn("expire upload failed", zap.Uint("upload", sessions[i].ID), zap.Error(err))
+			continue
+		}
+		app.Logger.Info("expired abandoned upload", zap.Uint("upload", sessions[i].ID))
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+// internal/api/users.go
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/auth"
+	"github.com/mohan2020coder/mSpace/internal/models"
+)
+
+// ---------------- Users ----------------
+
+type createUserReq struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+	IsAdmin  bool   `json:"is_admin"`
+}
+
+func meHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := requireUser(app, c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func createUserHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createUserReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		hash, err := auth.HashPassword(req.Password)
+		if err != nil {
+			app.Logger.Error("password hash failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+			return
+		}
+		user := models.User{Username: req.Username, PasswordHash: hash, IsAdmin: req.IsAdmin}
+		err = app.DB.Create(&user).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
+			return
+		}
+		if err != nil {
+			app.Logger.Error("db create user failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+			return
+		}
+		c.JSON(http.StatusCreated, user)
+	}
+}
+
+func listUsersHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := parseListParams(c, "title", "date")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var total int64
+		if err := app.DB.Model(&models.User{}).Count(&total).Error; err != nil {
+			app.Logger.Error("db count users failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list users"})
+			return
+		}
+		var users []models.User
+		err = app.DB.Preload("Groups").
+			Order(page.orderBy(map[string]string{"date": "created_at", "title": "username"})).
+			Limit(page.Limit).Offset(page.Offset).
+			Find(&users).Error
+		if err != nil {
+			app.Logger.Error("db list users failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list users"})
+			return
+		}
+		setPageHeaders(c, page, total)
+		c.JSON(http.StatusOK, users)
+	}
+}
+
+// ---------------- Groups ----------------
+
+type groupMemberReq struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+func createGroupHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var group models.Group
+		if err := c.ShouldBindJSON(&group); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		group.Users = nil
+		if err := app.DB.Create(&group).Error; err != nil {
+			app.Logger.Error("db create group failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create group"})
+			return
+		}
+		c.JSON(http.StatusCreated, group)
+	}
+}
+
+func listGroupsHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := parseListParams(c, "title", "date")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var total int64
+		if err := app.DB.Model(&models.Group{}).Count(&total).Error; err != nil {
+			app.Logger.Error("db count groups failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list groups"})
+			return
+		}
+		var groups []models.Group
+		err = app.DB.Preload("Users").
+			Order(page.orderBy(map[string]string{"date": "created_at", "title": "name"})).
+			Limit(page.Limit).Offset(page.Offset).
+			Find(&groups).Error
+		if err != nil {
+			app.Logger.Error("db list groups failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list groups"})
+			return
+		}
+		setPageHeaders(c, page, total)
+		c.JSON(http.StatusOK, groups)
+	}
+}
+
+func addGroupMemberHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var group models.Group
+		if err := app.DB.First(&group, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+		var req groupMemberReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var user models.User
+		if err := app.DB.First(&user, req.UserID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		if err := app.DB.Model(&group).Association("Users").Append(&user); err != nil {
+			app.Logger.Error("db add group member failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add member"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "member added"})
+	}
+}
+
+func removeGroupMemberHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var group models.Group
+		if err := app.DB.First(&group, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+		user := models.User{}
+		user.ID = uint(userID)
+		if err := app.DB.Model(&group).Association("Users").Delete(&user); err != nil {
+			app.Logger.Error("db remove group member failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove member"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+	}
+}
+
+// ---------------- Policies ----------------
+
+type createPolicyReq struct {
+	ResourceType string `json:"resource_type" binding:"required,oneof=COMMUNITY COLLECTION"`
+	ResourceID   uint   `json:"resource_id" binding:"required"`
+	Action       string `json:"action" binding:"required,oneof=READ SUBMIT REVIEW ADMIN"`
+	UserID       *uint  `json:"user_id"`
+	GroupID      *uint  `json:"group_id"`
+}
+
+// authorizePolicyResource requires ADMIN on the resource a policy is attached to
+func authorizePolicyResource(app *App, c *gin.Context, resourceType string, resourceID uint) bool {
+	if resourceType == models.ResourceCommunity {
+		return authorizeCommunity(app, c, resourceID, models.ActionAdmin)
+	}
+	return authorizeCollection(app, c, resourceID, models.ActionAdmin)
+}
+
+func listPoliciesHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resourceType := c.Query("resource_type")
+		resourceID, _ := strconv.Atoi(c.Query("resource_id"))
+		if resourceType == "" || resourceID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "resource_type and resource_id required"})
+			return
+		}
+		if resourceType != models.ResourceCommunity && resourceType != models.ResourceCollection {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "resource_type must be COMMUNITY or COLLECTION"})
+			return
+		}
+		if !authorizePolicyResource(app, c, resourceType, uint(resourceID)) {
+			return
+		}
+		var policies []models.ResourcePolicy
+		if err := app.DB.Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).Find(&policies).Error; err != nil {
+			app.Logger.Error("db list policies failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list policies"})
+			return
+		}
+		c.JSON(http.StatusOK, policies)
+	}
+}
+
+func createPolicyHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createPolicyReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if (req.UserID == nil) == (req.GroupID == nil) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of user_id or group_id required"})
+			return
+		}
+
+		var exists int64
+		if req.ResourceType == models.ResourceCommunity {
+			app.DB.Model(&models.Community{}).Where("id = ?", req.ResourceID).Count(&exists)
+		} else {
+			app.DB.Model(&models.Collection{}).Where("id = ?", req.ResourceID).Count(&exists)
+		}
+		if exists == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "resource not found"})
+			return
+		}
+		if !authorizePolicyResource(app, c, req.ResourceType, req.ResourceID) {
+			return
+		}
+
+		policy := models.ResourcePolicy{
+			ResourceType: req.ResourceType,
+			ResourceID:   req.ResourceID,
+			Action:       req.Action,
+			UserID:       req.UserID,
+			GroupID:      req.GroupID,
+		}
+		if err := app.DB.Create(&policy).Error; err != nil {
+			app.Logger.Error("db create policy failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create policy"})
+			return
+		}
+		c.JSON(http.StatusCreated, policy)
+	}
+}
+
+func deletePolicyHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var policy models.ResourcePolicy
+		if err := app.DB.First(&policy, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+			return
+		}
+		if !authorizePolicyResource(app, c, policy.ResourceType, policy.ResourceID) {
+			return
+		}
+		if err := app.DB.Delete(&policy).Error; err != nil {
+			app.Logger.Error("db delete policy failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete policy"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "policy deleted"})
+	}
+}
@@ -0,0 +1,192 @@
+// internal/api/visibility.go
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/search"
+)
+
+const embargoCheckInterval = time.Minute
+
+// optionalAuth attaches claims when a bearer token is sent but lets anonymous callers through
+func optionalAuth(app *App) gin.HandlerFunc {
+	required := authRequired(app)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
+
+// itemAccess describes which items the caller may see
+type itemAccess struct {
+	User          *models.User
+	CollectionIDs []uint
+}
+
+func (a itemAccess) isAdmin() bool {
+	return a.User != nil && a.User.IsAdmin
+}
+
+func (a itemAccess) userID() uint {
+	if a.User == nil {
+		return 0
+	}
+	return a.User.ID
+}
+
+// searchAccess converts the access to the filter understood by the search index
+func (a itemAccess) searchAccess() search.Access {
+	return search.Access{All: a.isAdmin(), UserID: a.userID(), CollectionIDs: a.CollectionIDs}
+}
+
+// resolveAccess loads the current user (if any) and the collections they are a member of
+func resolveAccess(app *App, c *gin.Context) (itemAccess, error) {
+	user, err := currentUser(app, c)
+	if err != nil {
+		return itemAccess{}, err
+	}
+	access := itemAccess{User: user}
+	if user == nil || user.IsAdmin {
+		return access, nil
+	}
+	access.CollectionIDs, err = app.memberCollectionIDs(user)
+	return access, err
+}
+
+// memberCollectionIDs returns collections where the user holds any policy,
+// directly or through the parent community
+func (app *App) memberCollectionIDs(user *models.User) ([]uint, error) {
+	var policies []models.ResourcePolicy
+	if err := app.DB.Scopes(principalScope(user)).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	var collectionIDs, communityIDs []uint
+	for _, p := range policies {
+		switch p.ResourceType {
+		case models.ResourceCollection:
+			collectionIDs = append(collectionIDs, p.ResourceID)
+		case models.ResourceCommunity:
+			communityIDs = append(communityIDs, p.ResourceID)
+		}
+	}
+	if len(communityIDs) > 0 {
+		var inherited []uint
+		if err := app.DB.Model(&models.Collection{}).Where("community_id IN ?", communityIDs).Pluck("id", &inherited).Error; err != nil {
+			return nil, err
+		}
+		collectionIDs = append(collectionIDs, inherited...)
+	}
+	return collectionIDs, nil
+}
+
+// visibleItems restricts an item query to what the caller may see
+func visibleItems(access itemAccess) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if access.isAdmin() {
+			return db
+		}
+		conds := []string{"visibility = ?", "(embargo_until IS NOT NULL AND embargo_until <= ?)"}
+		args := []any{models.VisibilityPublic, time.Now()}
+		if uid := access.userID(); uid > 0 {
+			conds = append(conds, "submitter_id = ?")
+			args = append(args, uid)
+		}
+		if len(access.CollectionIDs) > 0 {
+			conds = append(conds, "collection_id IN ?")
+			args = append(args, access.CollectionIDs)
+		}
+		return db.Where(strings.Join(conds, " OR "), args...)
+	}
+}
+
+// canViewItem mirrors visibleItems for an already loaded item
+func canViewItem(access itemAccess, item *models.Item) bool {
+	if access.isAdmin() || item.Visibility == models.VisibilityPublic {
+		return true
+	}
+	if item.EmbargoUntil != nil && !item.EmbargoUntil.After(time.Now()) {
+		return true
+	}
+	if uid := access.userID(); uid > 0 && item.SubmitterID == uid {
+		return true
+	}
+	for _, cid := range access.CollectionIDs {
+		if cid == item.CollectionID {
+			return true
+		}
+	}
+	return false
+}
+
+// loadVisibleItem fetches an item by the :id param and writes 404 when the caller may not see it
+func loadVisibleItem(app *App, c *gin.Context) (*models.Item, bool) {
+	access, err := resolveAccess(app, c)
+	if err != nil {
+		app.Logger.Error("resolve access failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+		return nil, false
+	}
+	var item models.Item
+	if err := app.DB.First(&item, c.Param("id")).Error; err != nil || !canViewItem(access, &item) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return nil, false
+	}
+	return &item, true
+}
+
+// RunEmbargoLifter periodically turns PRIVATE items whose embargo has passed into PUBLIC ones
+func (app *App) RunEmbargoLifter(ctx context.Context) {
+	ticker := time.NewTicker(embargoCheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := app.liftEmbargoes(); err != nil {
+			app.Logger.Error("embargo lift failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (app *App) liftEmbargoes() error {
+	var items []models.Item
+	err := app.DB.Where("visibility = ? AND embargo_until IS NOT NULL AND embargo_until <= ?", models.VisibilityPrivate, time.Now()).
+		Find(&items).Error
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		item := &items[i]
+		before := *item
+		item.Visibility = models.VisibilityPublic
+		err := app.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(item).Update("visibility", models.VisibilityPublic).Error; err != nil {
+				return err
+			}
+			if err := recordItemEvent(tx, nil, EventEmbargoLifted, &before, item, "", nil); err != nil {
+				return err
+			}
+			return app.queueIndex(tx, item.ID)
+		})
+		if err != nil {
+			return err
+		}
+		app.Logger.Info("embargo lifted", zap.Uint("item", item.ID))
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+// internal/api/workflow.go
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+	"github.com/mohan2020coder/mSpace/internal/workflow"
+)
+
+type reviewReq struct {
+	Comment string `json:"comment"`
+}
+
+// bindReview reads the optional review body; when required is set an empty comment is a 400
+func bindReview(c *gin.Context, required bool) (reviewReq, bool) {
+	var req reviewReq
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if required && req.Comment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "comment required"})
+		return req, false
+	}
+	return req, true
+}
+
+// loadItem fetches the item named by the :id param or writes a 404
+func loadItem(app *App, c *gin.Context) (*models.Item, bool) {
+	var item models.Item
+	if err := app.DB.First(&item, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return nil, false
+	}
+	return &item, true
+}
+
+var errConcurrentChange = errors.New("item status changed concurrently")
+
+// transitionItem moves the item to next only if nobody changed its status meanwhile
+// and records the move in the item history. Illegal or conflicting moves are answered with 409.
+func transitionItem(app *App, c *gin.Context, item *models.Item, action, next string, step int, comment string) bool {
+	if err := workflow.Transition(item.Status, next); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return false
+	}
+	actor, _ := currentUser(app, c)
+	before := *item
+	after := *item
+	after.Status, after.WorkflowStep, after.ReviewComment = next, step, comment
+
+	err := app.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Item{}).
+			Where("id = ? AND status = ? AND workflow_step = ?", item.ID, item.Status, item.WorkflowStep).
+			Updates(map[string]any{"status": next, "workflow_step": step, "review_comment": comment})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errConcurrentChange
+		}
+		if err := recordItemEvent(tx, actor, action, &before, &after, comment, nil); err != nil {
+			return err
+		}
+		return app.queueIndex(tx, item.ID)
+	})
+	if errors.Is(err, errConcurrentChange) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return false
+	}
+	if err != nil {
+		app.Logger.Error("db status update failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update item status"})
+		return false
+	}
+	*item = after
+	return true
+}
+
+func (app *App) workflowSteps(collectionID uint) ([]models.WorkflowStep, error) {
+	var steps []models.WorkflowStep
+	err := app.DB.Where("collection_id = ?", collectionID).Order("position").Find(&steps).Error
+	return steps, err
+}
+
+// authorizeReviewStep checks the current user may act on the given 1-based reviewer step.
+// Collections without configured steps have a single implicit step open to REVIEW holders.
+func authorizeReviewStep(app *App, c *gin.Context, item *models.Item, steps []models.WorkflowStep, step int) bool {
+	if step < 1 || step > len(steps) || steps[step-1].GroupID == nil {
+		return authorizeCollection(app, c, item.CollectionID, models.ActionReview)
+	}
+
+	user, ok := requireUser(app, c)
+	if !ok {
+		return false
+	}
+	groupID := *steps[step-1].GroupID
+	for _, gid := range groupIDs(user) {
+		if gid == groupID {
+			return true
+		}
+	}
+	allowed, err := app.canCollection(user, item.CollectionID, models.ActionAdmin)
+	if err != nil {
+		app.Logger.Error("permission check failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "permission check failed"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a reviewer for step " + steps[step-1].Name})
+		return false
+	}
+	return true
+}
+
+// reviewStepFor returns the step a reviewer acts on: the current one while IN_REVIEW, the first otherwise
+func reviewStepFor(item *models.Item) int {
+	if item.Status == workflow.StateInReview && item.WorkflowStep > 0 {
+		return item.WorkflowStep
+	}
+	return 1
+}
+
+// ---------------- Transitions ----------------
+
+// submitItemHandler sends the submitter's own work to review; collection admins may submit any item
+func submitItemHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		user, ok := requireUser(app, c)
+		if !ok {
+			return
+		}
+		action := models.ActionSubmit
+		if item.SubmitterID != user.ID {
+			action = models.ActionAdmin
+		}
+		if !authorizeCollection(app, c, item.CollectionID, action) {
+			return
+		}
+		if !transitionItem(app, c, item, EventSubmit, workflow.StateSubmitted, 0, "") {
+			return
+		}
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+// startReviewHandler takes a submitted item into the first reviewer step
+func startReviewHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		steps, err := app.workflowSteps(item.CollectionID)
+		if err != nil {
+			app.Logger.Error("db load workflow failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load workflow"})
+			return
+		}
+		if !authorizeReviewStep(app, c, item, steps, 1) {
+			return
+		}
+		if !transitionItem(app, c, item, EventStartReview, workflow.StateInReview, 1, item.ReviewComment) {
+			return
+		}
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+func requestChangesHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		req, ok := bindReview(c, true)
+		if !ok {
+			return
+		}
+		steps, err := app.workflowSteps(item.CollectionID)
+		if err != nil {
+			app.Logger.Error("db load workflow failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load workflow"})
+			return
+		}
+		if !authorizeReviewStep(app, c, item, steps, reviewStepFor(item)) {
+			return
+		}
+		if !transitionItem(app, c, item, EventRequestChanges, workflow.StateChangesRequested, 0, req.Comment) {
+			return
+		}
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+// withdrawItemHandler lets the submitter retract unpublished work and collection admins retract published items
+func withdrawItemHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, ok := loadItem(app, c)
+		if !ok {
+			return
+		}
+		req, ok := bindReview(c, false)
+		if !ok {
+			return
+		}
+		user, ok := requireUser(app, c)
+		if !ok {
+			return
+		}
+		if item.Status == workflow.StatePublished || item.SubmitterID != user.ID {
+			if !authorizeCollection(app, c, item.CollectionID, models.ActionAdmin) {
+				return
+			}
+		}
+		if !transitionItem(app, c, item, EventWithdraw, workflow.StateWithdrawn, 0, req.Comment) {
+			return
+		}
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+// ---------------- Workflow steps ----------------
+
+type workflowStepReq struct {
+	Name    string `json:"name" binding:"required"`
+	GroupID *uint  `json:"group_id"`
+}
+
+func getWorkflowStepsHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var col models.Collection
+		if err := app.DB.First(&col, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "collection not found"})
+			return
+		}
+		steps, err := app.workflowSteps(col.ID)
+		if err != nil {
+			app.Logger.Error("db load workflow failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load workflow"})
+			return
+		}
+		c.JSON(http.StatusOK, steps)
+	}
+}
+
+// putWorkflowStepsHandler replaces the ordered reviewer steps of a collection
+func putWorkflowStepsHandler(app *App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var col models.Collection
+		if err := app.DB.First(&col, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "collection not found"})
+			return
+		}
+		if !authorizeCollection(app, c, col.ID, models.ActionAdmin) {
+			return
+		}
+		var req []workflowStepReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		steps := make([]models.WorkflowStep, len(req))
+		for i, r := range req {
+			steps[i] = models.WorkflowStep{CollectionID: col.ID, Position: i + 1, Name: r.Name, GroupID: r.GroupID}
+		}
+		err := app.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Where("collection_id = ?", col.ID).Delete(&models.WorkflowStep{}).Error; err != nil {
+				return err
+			}
+			if len(steps) == 0 {
+				return nil
+			}
+			return tx.Create(&steps).Error
+		})
+		if err != nil {
+			app.Logger.Error("db save workflow failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save workflow"})
+			return
+		}
+		c.JSON(http.StatusOK, steps)
+	}
+}
@@ -0,0 +1,102 @@
+// internal/auth/auth.go
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// MinSecretLength is the shortest signing secret accepted, the size of an HS256 key
+const MinSecretLength = 32
+
+// knownSecrets are signing secrets that have been published, e.g. in sample configs
+var knownSecrets = map[string]bool{
+	"supersecretkey": true,
+	"secret":         true,
+	"changeme":       true,
+}
+
+// ValidateSecret rejects signing secrets that are published or too short to resist
+// guessing; anyone who knows the secret can sign a token for any user
+func ValidateSecret(secret string) error {
+	if secret == "" {
+		return errors.New("jwt secret is not set")
+	}
+	if knownSecrets[secret] {
+		return errors.New("jwt secret is a published default")
+	}
+	if len(secret) < MinSecretLength {
+		return fmt.Errorf("jwt secret is %d bytes, need at least %d", len(secret), MinSecretLength)
+	}
+	return nil
+}
+
+// Claims carried inside every access token
+type Claims struct {
+	UserID   uint   `json:"uid"`
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// IssueToken signs a new HS256 token for the user valid for expiry
+func IssueToken(secret string, userID uint, username string, expiry time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(expiry)
+
+	claims := Claims{
+		UserID:   userID,
+		Username: username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseToken validates signature and expiry and returns the claims
+func ParseToken(secret, tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (any, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// HashPassword returns a bcrypt hash suitable for storing in the DB
+func HashPassword(password string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// CheckPassword compares a bcrypt hash with a plain password
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
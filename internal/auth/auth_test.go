@@ -0,0 +1,88 @@
+// internal/auth/auth_test.go
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = strings.Repeat("k", MinSecretLength)
+
+func TestTokenRoundTrip(t *testing.T) {
+	token, expiresAt, err := IssueToken(testSecret, 42, "asha", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(expiresAt); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("expires in %s, want about an hour", d)
+	}
+	claims, err := ParseToken(testSecret, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 42 || claims.Username != "asha" || claims.Subject != "42" {
+		t.Errorf("claims %+v", claims)
+	}
+}
+
+func TestParseTokenExpired(t *testing.T) {
+	token, _, err := IssueToken(testSecret, 42, "asha", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(testSecret, token); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("got %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestParseTokenWrongKey(t *testing.T) {
+	token, _, err := IssueToken(testSecret, 42, "asha", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(strings.Repeat("x", MinSecretLength), token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestParseTokenWrongSigningMethod(t *testing.T) {
+	claims := Claims{UserID: 1, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}}
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodHS512, jwt.SigningMethodNone} {
+		key := any([]byte(testSecret))
+		if method == jwt.SigningMethodNone {
+			key = jwt.UnsafeAllowNoneSignatureType
+		}
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseToken(testSecret, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: got %v, want ErrInvalidToken", method.Alg(), err)
+		}
+	}
+}
+
+func TestParseTokenWithoutExpiry(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: 1}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(testSecret, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestValidateSecret(t *testing.T) {
+	for _, secret := range []string{"", "supersecretkey", strings.Repeat("a", MinSecretLength-1)} {
+		if ValidateSecret(secret) == nil {
+			t.Errorf("secret %q accepted", secret)
+		}
+	}
+	if err := ValidateSecret(testSecret); err != nil {
+		t.Errorf("secret of %d bytes rejected: %v", MinSecretLength, err)
+	}
+}
@@ -18,7 +18,8 @@ type DatabaseCfg struct {
 }
 
 type StorageCfg struct {
-	Provider  string `mapstructure:"provider"`
+	Provider  string `mapstructure:"provider"` // minio, fs or memory
+	Path      string `mapstructure:"path"`     // root directory for the fs provider
 	Endpoint  string `mapstructure:"endpoint"`
 	Bucket    string `mapstructure:"bucket"`
 	AccessKey string `mapstructure:"access_key"`
@@ -27,8 +28,63 @@ type StorageCfg struct {
 }
 
 type AuthCfg struct {
-	JWTSecret   string        `mapstructure:"jwt_secret"`
-	TokenExpiry time.Duration `mapstructure:"token_expiry"`
+	JWTSecret     string        `mapstructure:"jwt_secret"`
+	TokenExpiry   time.Duration `mapstructure:"token_expiry"`
+	AdminUsername string        `mapstructure:"admin_username"`
+	AdminPassword string        `mapstructure:"admin_password"`
+}
+
+// FixityCfg controls the background checksum verifier
+type FixityCfg struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Interval  time.Duration `mapstructure:"interval"`   // pause between batches
+	BatchSize int           `mapstructure:"batch_size"` // bitstreams verified per batch
+	MaxAge    time.Duration `mapstructure:"max_age"`    // re-verify bitstreams last checked longer ago than this
+}
+
+// UploadCfg controls resumable multipart uploads
+type UploadCfg struct {
+	PartSize        int64         `mapstructure:"part_size"`        // suggested part size; S3 needs at least 5 MiB except for the last part
+	SessionTTL      time.Duration `mapstructure:"session_ttl"`      // idle time after which a session is abandoned
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"` // how often abandoned sessions are aborted
+}
+
+// JobsCfg controls the background job workers
+type JobsCfg struct {
+	Workers      int           `mapstructure:"workers"`
+	PollInterval time.Duration `mapstructure:"poll_interval"` // idle wait before looking for new jobs
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	Backoff      time.Duration `mapstructure:"backoff"`      // first retry delay, doubled on every attempt
+	LockTimeout  time.Duration `mapstructure:"lock_timeout"` // running jobs older than this are requeued
+}
+
+// TikaCfg configures the Apache Tika server used for OCR and office formats
+type TikaCfg struct {
+	URL              string        `mapstructure:"url"` // base URL, /tika and /version are appended
+	Timeout          time.Duration `mapstructure:"timeout"`
+	OCRLanguage      string        `mapstructure:"ocr_language"`      // Tesseract languages, e.g. "eng+hin"
+	MaxFileSize      int64         `mapstructure:"max_file_size"`     // larger files are not sent to Tika
+	FailureThreshold int           `mapstructure:"failure_threshold"` // consecutive failures that open the circuit
+	Cooldown         time.Duration `mapstructure:"cooldown"`          // how long the open circuit rejects calls
+}
+
+// SearchCfg configures the Bleve index and its reconciliation with the database
+type SearchCfg struct {
+	IndexPath         string        `mapstructure:"index_path"`
+	Reconcile         bool          `mapstructure:"reconcile"` // periodically repair drift between index and database
+	ReconcileInterval time.Duration `mapstructure:"reconcile_interval"`
+}
+
+// EmbeddingCfg configures the model that embeds item chunks for semantic search
+type EmbeddingCfg struct {
+	Provider     string        `mapstructure:"provider"` // ollama or fake; empty disables semantic search
+	URL          string        `mapstructure:"url"`
+	Model        string        `mapstructure:"model"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	ChunkWords   int           `mapstructure:"chunk_words"`   // words per embedded chunk
+	ChunkOverlap int           `mapstructure:"chunk_overlap"` // words repeated from the previous chunk
+	BatchSize    int           `mapstructure:"batch_size"`    // chunks per embedding request
+	Candidates   int           `mapstructure:"candidates"`    // hits taken from each ranking before fusion
 }
 
 type LoggingCfg struct {
@@ -37,16 +93,28 @@ type LoggingCfg struct {
 }
 
 type Config struct {
-	Server   ServerCfg   `mapstructure:"server"`
-	Database DatabaseCfg `mapstructure:"database"`
-	Storage  StorageCfg  `mapstructure:"storage"`
-	Auth     AuthCfg     `mapstructure:"auth"`
-	Logging  LoggingCfg  `mapstructure:"logging"`
+	Server    ServerCfg    `mapstructure:"server"`
+	Database  DatabaseCfg  `mapstructure:"database"`
+	Storage   StorageCfg   `mapstructure:"storage"`
+	Auth      AuthCfg      `mapstructure:"auth"`
+	Logging   LoggingCfg   `mapstructure:"logging"`
+	Fixity    FixityCfg    `mapstructure:"fixity"`
+	Uploads   UploadCfg    `mapstructure:"uploads"`
+	Jobs      JobsCfg      `mapstructure:"jobs"`
+	Tika      TikaCfg      `mapstructure:"tika"`
+	Search    SearchCfg    `mapstructure:"search"`
+	Embedding EmbeddingCfg `mapstructure:"embedding"`
 }
 
 func LoadConfig(path string) (*Config, error) {
 	v := viper.New()
 	v.SetConfigFile(path)
+	v.SetDefault("fixity.enabled", true)
+	v.SetDefault("search.reconcile", true)
+	// The token signing secret and the bootstrap admin password are never committed;
+	// they only come from the environment
+	v.BindEnv("auth.jwt_secret", "MSPACE_JWT_SECRET")
+	v.BindEnv("auth.admin_password", "MSPACE_ADMIN_PASSWORD")
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
@@ -64,6 +132,82 @@ func LoadConfig(path string) (*Config, error) {
 			cfg.Auth.TokenExpiry = d
 		}
 	}
+	if cfg.Auth.TokenExpiry <= 0 {
+		cfg.Auth.TokenExpiry = 24 * time.Hour
+	}
+
+	if cfg.Fixity.Interval <= 0 {
+		cfg.Fixity.Interval = time.Hour
+	}
+	if cfg.Fixity.BatchSize <= 0 {
+		cfg.Fixity.BatchSize = 100
+	}
+	if cfg.Fixity.MaxAge <= 0 {
+		cfg.Fixity.MaxAge = 30 * 24 * time.Hour
+	}
+	if cfg.Uploads.PartSize < 5<<20 {
+		cfg.Uploads.PartSize = 16 << 20
+	}
+	if cfg.Uploads.SessionTTL <= 0 {
+		cfg.Uploads.SessionTTL = 24 * time.Hour
+	}
+	if cfg.Uploads.CleanupInterval <= 0 {
+		cfg.Uploads.CleanupInterval = 15 * time.Minute
+	}
+	if cfg.Jobs.Workers <= 0 {
+		cfg.Jobs.Workers = 2
+	}
+	if cfg.Jobs.PollInterval <= 0 {
+		cfg.Jobs.PollInterval = 2 * time.Second
+	}
+	if cfg.Jobs.MaxAttempts <= 0 {
+		cfg.Jobs.MaxAttempts = 5
+	}
+	if cfg.Jobs.Backoff <= 0 {
+		cfg.Jobs.Backoff = 30 * time.Second
+	}
+	if cfg.Jobs.LockTimeout <= 0 {
+		cfg.Jobs.LockTimeout = 30 * time.Minute
+	}
+	if cfg.Tika.URL == "" {
+		cfg.Tika.URL = "http://localhost:9998"
+	}
+	if cfg.Tika.Timeout <= 0 {
+		cfg.Tika.Timeout = 5 * time.Minute
+	}
+	if cfg.Tika.FailureThreshold <= 0 {
+		cfg.Tika.FailureThreshold = 5
+	}
+	if cfg.Tika.Cooldown <= 0 {
+		cfg.Tika.Cooldown = time.Minute
+	}
+	if cfg.Search.IndexPath == "" {
+		cfg.Search.IndexPath = "./bleve_index"
+	}
+	if cfg.Search.ReconcileInterval <= 0 {
+		cfg.Search.ReconcileInterval = 6 * time.Hour
+	}
+	if cfg.Embedding.URL == "" {
+		cfg.Embedding.URL = "http://localhost:11434"
+	}
+	if cfg.Embedding.Model == "" {
+		cfg.Embedding.Model = "nomic-embed-text"
+	}
+	if cfg.Embedding.Timeout <= 0 {
+		cfg.Embedding.Timeout = 2 * time.Minute
+	}
+	if cfg.Embedding.ChunkWords <= 0 {
+		cfg.Embedding.ChunkWords = 200
+	}
+	if cfg.Embedding.ChunkOverlap < 0 || cfg.Embedding.ChunkOverlap >= cfg.Embedding.ChunkWords {
+		cfg.Embedding.ChunkOverlap = 0
+	}
+	if cfg.Embedding.BatchSize <= 0 {
+		cfg.Embedding.BatchSize = 32
+	}
+	if cfg.Embedding.Candidates <= 0 {
+		cfg.Embedding.Candidates = 100
+	}
 
 	return &cfg, nil
 }
@@ -9,7 +9,8 @@ import (
 )
 
 func Init(dsn string) *gorm.DB {
-	gdb, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError maps driver errors such as unique violations to gorm.ErrDuplicatedKey
+	gdb, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("failed to open database: %v", err)
 	}
@@ -0,0 +1,105 @@
+// internal/embed/embed.go
+package embed
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mohan2020coder/mSpace/internal/config"
+)
+
+// Provider turns texts into vectors; texts with similar meaning get vectors with a
+// high cosine similarity
+type Provider interface {
+	// Model names the vector space; vectors of different models are never compared
+	Model() string
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// New builds the provider named in the config; an empty provider disables semantic
+// search and returns nil
+func New(cfg config.EmbeddingCfg) (Provider, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "ollama":
+		return NewOllama(cfg.URL, cfg.Model, cfg.Timeout), nil
+	case "fake":
+		return &Fake{Dims: 256}, nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.Provider)
+	}
+}
+
+// Chunk splits text into windows of at most size words, each starting overlap words
+// before the end of the previous one
+func Chunk(text string, size, overlap int) []string {
+	words := strings.Fields(text)
+	if size <= 0 || len(words) == 0 {
+		return nil
+	}
+	step := size - overlap
+	if step <= 0 {
+		step = size
+	}
+	var chunks []string
+	for start := 0; start < len(words); start += step {
+		end := min(start+size, len(words))
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+	}
+	return chunks
+}
+
+// Normalize scales v to unit length in place, so cosine similarity is a dot product
+func Normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// Dot is the cosine similarity of two normalized vectors; vectors of different
+// lengths score 0
+func Dot(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// Encode packs a vector as little-endian float32s for storage
+func Encode(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(x))
+	}
+	return b
+}
+
+// Decode unpacks a vector written by Encode
+func Decode(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v
+}
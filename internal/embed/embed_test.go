@@ -0,0 +1,79 @@
+// internal/embed/embed_test.go
+package embed
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	cases := []struct {
+		text          string
+		size, overlap int
+		want          []string
+	}{
+		{"a b c d e f g", 3, 1, []string{"a b c", "c d e", "e f g"}},
+		{"a b c d e f", 3, 1, []string{"a b c", "c d e", "e f"}},
+		{"a b c d", 2, 0, []string{"a b", "c d"}},
+		{"a b c", 5, 2, []string{"a b c"}},
+		{"a b c d", 2, 2, []string{"a b", "c d"}}, // overlap as large as size is ignored
+		{"  a\n\tb  ", 4, 1, []string{"a b"}},
+		{"   ", 3, 1, nil},
+		{"a b", 0, 0, nil},
+	}
+	for _, tc := range cases {
+		if got := Chunk(tc.text, tc.size, tc.overlap); !slices.Equal(got, tc.want) {
+			t.Errorf("Chunk(%q, %d, %d) = %q, want %q", tc.text, tc.size, tc.overlap, got, tc.want)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	v := []float32{0, 1, -2.5, float32(math.Pi)}
+	if got := Decode(Encode(v)); !slices.Equal(got, v) {
+		t.Fatalf("round trip: got %v, want %v", got, v)
+	}
+}
+
+func TestFake(t *testing.T) {
+	f := &Fake{Dims: 256}
+	if f.Model() != "fake/256" {
+		t.Fatalf("model %q", f.Model())
+	}
+	texts := []string{
+		"Bail granted to the accused",
+		"bail, GRANTED to the accused!",
+		"anticipatory bail for the accused refused",
+		"land acquisition compensation enhanced",
+	}
+	v, err := f.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != len(texts) {
+		t.Fatalf("got %d vectors for %d texts", len(v), len(texts))
+	}
+	for i := range v {
+		if len(v[i]) != 256 {
+			t.Fatalf("vector %d has %d dims", i, len(v[i]))
+		}
+		if n := Dot(v[i], v[i]); math.Abs(n-1) > 1e-5 {
+			t.Errorf("vector %d not normalized: |v|² = %f", i, n)
+		}
+	}
+	// Case and punctuation are ignored, so these embed identically
+	if s := Dot(v[0], v[1]); math.Abs(s-1) > 1e-5 {
+		t.Errorf("same words should embed identically, similarity %f", s)
+	}
+	// Shared words make texts closer than unrelated ones
+	if related, unrelated := Dot(v[0], v[2]), Dot(v[0], v[3]); related <= unrelated {
+		t.Errorf("related %f should exceed unrelated %f", related, unrelated)
+	}
+
+	again, _ := f.Embed(context.Background(), texts[:1])
+	if !slices.Equal(again[0], v[0]) {
+		t.Errorf("embedding is not deterministic")
+	}
+}
@@ -0,0 +1,41 @@
+// internal/embed/fake.go
+package embed
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// Fake is a deterministic provider for tests and offline setups. Each word is hashed
+// into one of Dims buckets, so texts sharing words are similar; it has no notion of
+// meaning beyond that.
+type Fake struct {
+	Dims int
+}
+
+func (f *Fake) Model() string { return fmt.Sprintf("fake/%d", f.Dims) }
+
+func (f *Fake) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, f.Dims)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, w := range words {
+			h := fnv.New32a()
+			h.Write([]byte(w))
+			sum := h.Sum32()
+			if sum&1 == 0 {
+				v[int(sum>>1)%f.Dims]++
+			} else {
+				v[int(sum>>1)%f.Dims]--
+			}
+		}
+		out[i] = Normalize(v)
+	}
+	return out, nil
+}
@@ -0,0 +1,72 @@
+// internal/embed/ollama.go
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds one embedding request when the config leaves it unset
+const defaultTimeout = 2 * time.Minute
+
+// Ollama embeds texts with a model served by an Ollama server
+type Ollama struct {
+	URL    string // server base URL, e.g. http://localhost:11434
+	Name   string // embedding model, e.g. nomic-embed-text
+	Client *http.Client
+}
+
+func NewOllama(url, model string, timeout time.Duration) *Ollama {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Ollama{
+		URL:    strings.TrimSuffix(url, "/"),
+		Name:   model,
+		Client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (o *Ollama) Model() string { return "ollama/" + o.Name }
+
+// Embed sends all texts in one /api/embed request
+func (o *Ollama) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(map[string]any{"model": o.Name, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.URL+"/api/embed", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ollama embed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("ollama embed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("ollama embed: decode response: %w", err)
+	}
+	if len(out.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama embed: got %d vectors for %d texts", len(out.Embeddings), len(texts))
+	}
+	return out.Embeddings, nil
+}
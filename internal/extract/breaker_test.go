@@ -0,0 +1,143 @@
+// internal/extract/breaker_test.go
+package extract
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mohan2020coder/mSpace/internal/config"
+)
+
+// expire ends the cooldown of an open breaker
+func expire(b *breaker) {
+	b.mu.Lock()
+	b.openUntil = time.Now().Add(-time.Second)
+	b.mu.Unlock()
+}
+
+func TestBreakerStateMachine(t *testing.T) {
+	b := newBreaker(2, time.Hour)
+
+	if !b.allow() || b.state() != "closed" {
+		t.Fatalf("new breaker should be closed")
+	}
+	b.failure()
+	if !b.allow() || b.state() != "closed" {
+		t.Fatalf("breaker opened below the threshold")
+	}
+	b.failure()
+	if b.allow() || b.state() != "open" {
+		t.Fatalf("breaker should open at the threshold, state %s", b.state())
+	}
+
+	// Cooldown over: exactly one probe goes through
+	expire(b)
+	if b.state() != "half-open" {
+		t.Fatalf("expected half-open, got %s", b.state())
+	}
+	if !b.allow() {
+		t.Fatalf("half-open breaker rejected the probe")
+	}
+	if b.allow() {
+		t.Fatalf("half-open breaker let a second call through while probing")
+	}
+
+	// A failed probe reopens the circuit
+	b.failure()
+	if b.allow() || b.state() != "open" {
+		t.Fatalf("failed probe should reopen the circuit, state %s", b.state())
+	}
+
+	// A successful probe closes it
+	expire(b)
+	if !b.allow() {
+		t.Fatalf("probe rejected after cooldown")
+	}
+	b.success()
+	if !b.allow() || !b.allow() || b.state() != "closed" {
+		t.Fatalf("successful probe should close the circuit, state %s", b.state())
+	}
+}
+
+func TestTikaSettlesBreakerOnClientErrors(t *testing.T) {
+	cases := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusUnsupportedMediaType, ErrUnprocessable},
+		{http.StatusUnprocessableEntity, ErrUnprocessable},
+		{http.StatusRequestEntityTooLarge, ErrTooLarge},
+	}
+	for _, tc := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+		}))
+		tika := NewTika(config.TikaCfg{URL: srv.URL, Timeout: time.Second, FailureThreshold: 1, Cooldown: time.Hour}, zap.NewNop())
+
+		// Open the circuit, then let the client error answer the half-open probe
+		tika.breaker.failure()
+		expire(tika.breaker)
+		_, err := tika.Extract(context.Background(), strings.NewReader("doc"), 3)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("status %d: got %v, want %v", tc.status, err, tc.want)
+		}
+		if s := tika.breaker.state(); s != "closed" {
+			t.Errorf("status %d: breaker %s after the probe, want closed", tc.status, s)
+		}
+		if _, err := tika.Extract(context.Background(), strings.NewReader("doc"), 3); errors.Is(err, ErrTikaUnavailable) {
+			t.Errorf("status %d: breaker still rejects calls: %v", tc.status, err)
+		}
+		srv.Close()
+	}
+}
+
+func TestTikaServerErrorsOpenBreaker(t *testing.T) {
+	// A wrong tika.url (404) or a proxy refusing the request (401, 403, 405) says
+	// nothing about the document, so it counts against Tika like a 5xx
+	statuses := []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusBadRequest,
+		http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusMethodNotAllowed}
+	for _, status := range statuses {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		tika := NewTika(config.TikaCfg{URL: srv.URL, Timeout: time.Second, FailureThreshold: 2, Cooldown: time.Hour}, zap.NewNop())
+
+		for i := 0; i < 2; i++ {
+			if _, err := tika.Extract(context.Background(), strings.NewReader("doc"), 3); !errors.Is(err, ErrTikaUnavailable) {
+				t.Errorf("status %d attempt %d: got %v, want ErrTikaUnavailable", status, i, err)
+			}
+		}
+		if s := tika.breaker.state(); s != "open" {
+			t.Errorf("status %d: breaker %s after repeated failures, want open", status, s)
+		}
+		srv.Close()
+	}
+}
+
+func TestTikaSuccessClosesBreaker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><div class="page"><p>one</p></div><div class="page"><p>two</p></div></body></html>`))
+	}))
+	defer srv.Close()
+	tika := NewTika(config.TikaCfg{URL: srv.URL, Timeout: time.Second, FailureThreshold: 1, Cooldown: time.Hour}, zap.NewNop())
+
+	tika.breaker.failure()
+	expire(tika.breaker)
+	text, err := tika.Extract(context.Background(), strings.NewReader("doc"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(text.Pages) != 2 {
+		t.Errorf("got %d pages, want 2", len(text.Pages))
+	}
+	if s := tika.breaker.state(); s != "closed" {
+		t.Errorf("breaker %s after a good response, want closed", s)
+	}
+}
@@ -0,0 +1,48 @@
+// internal/extract/builtin.go
+package extract
+
+import (
+	"context"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// Default returns a registry with the built-in extractors: PDF (digital text with
+// Tika OCR fallback), DOCX, ODT, HTML, plain text, RTF and images through Tika
+func Default(tika *Tika, logger *zap.Logger) *Registry {
+	r := NewRegistry()
+	r.Register("application/pdf", PDF(tika, logger), ".pdf")
+	r.Register("application/vnd.openxmlformats-officedocument.wordprocessingml.document", SinglePage(extractDOCX), ".docx")
+	r.Register("application/vnd.oasis.opendocument.text", SinglePage(extractODT), ".odt")
+	r.Register("text/html", SinglePage(extractHTML), ".html", ".htm")
+	r.Register("application/xhtml+xml", SinglePage(extractHTML), ".xhtml")
+	r.Register("text/plain", SinglePage(extractText), ".txt", ".text")
+	r.Register("application/rtf", SinglePage(extractRTF), ".rtf")
+	r.Register("text/rtf", SinglePage(extractRTF))
+	for mt, exts := range map[string][]string{
+		"image/png":  {".png"},
+		"image/jpeg": {".jpg", ".jpeg"},
+		"image/tiff": {".tif", ".tiff"},
+		"image/gif":  {".gif"},
+		"image/bmp":  {".bmp"},
+		"image/webp": {".webp"},
+	} {
+		r.Register(mt, tika, exts...)
+	}
+	return r
+}
+
+func extractText(ctx context.Context, src Source, size int64) (string, error) {
+	b, err := io.ReadAll(src)
+	if err != nil {
+		return "", err
+	}
+	s := string(b)
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+	return normalizeLines(strings.ReplaceAll(s, "\r\n", "\n")), nil
+}
@@ -0,0 +1,103 @@
+// internal/extract/extract.go
+package extract
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupported is returned for formats without a registered extractor
+var ErrUnsupported = errors.New("no text extractor for format")
+
+// Source is random-access file content, e.g. a stored object
+type Source interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+// Text is extracted document text split into pages; formats without pages have one
+type Text struct {
+	Pages []string
+}
+
+// String joins the pages into the document's full text
+func (t Text) String() string {
+	return strings.Join(t.Pages, "\n")
+}
+
+// Extractor turns a document into plain text
+type Extractor interface {
+	Extract(ctx context.Context, src Source, size int64) (Text, error)
+}
+
+// SinglePage adapts an extractor for formats without page boundaries
+type SinglePage func(ctx context.Context, src Source, size int64) (string, error)
+
+func (f SinglePage) Extract(ctx context.Context, src Source, size int64) (Text, error) {
+	s, err := f(ctx, src, size)
+	if err != nil {
+		return Text{}, err
+	}
+	return Text{Pages: []string{s}}, nil
+}
+
+// Registry maps MIME types to extractors; file extensions are used when the
+// declared type is missing or generic
+type Registry struct {
+	mu     sync.RWMutex
+	byMIME map[string]Extractor
+	byExt  map[string]string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{byMIME: map[string]Extractor{}, byExt: map[string]string{}}
+}
+
+// Register sets the extractor for mimeType and maps the given extensions (".docx") to it
+func (r *Registry) Register(mimeType string, e Extractor, exts ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byMIME[mimeType] = e
+	for _, ext := range exts {
+		r.byExt[strings.ToLower(ext)] = mimeType
+	}
+}
+
+// Lookup finds the extractor for a file, preferring its declared MIME type
+func (r *Registry) Lookup(mimeType, fileName string) (Extractor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if mt, _, err := mime.ParseMediaType(mimeType); err == nil {
+		if e, ok := r.byMIME[mt]; ok {
+			return e, true
+		}
+	}
+	if mt, ok := r.byExt[strings.ToLower(filepath.Ext(fileName))]; ok {
+		return r.byMIME[mt], true
+	}
+	return nil, false
+}
+
+// Extract runs the matching extractor, returning ErrUnsupported when there is none
+func (r *Registry) Extract(ctx context.Context, mimeType, fileName string, src Source, size int64) (Text, error) {
+	e, ok := r.Lookup(mimeType, fileName)
+	if !ok {
+		return Text{}, ErrUnsupported
+	}
+	return e.Extract(ctx, src, size)
+}
+
+// normalizeLines collapses runs of whitespace inside lines and trims them
+func normalizeLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,147 @@
+// internal/extract/extract_test.go
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// zipped builds an in-memory zip package with one member
+func zipped(t *testing.T, member, content string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func run(t *testing.T, f SinglePage, src *bytes.Reader) string {
+	t.Helper()
+	s, err := f(context.Background(), src, src.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestExtractDOCX(t *testing.T) {
+	doc := `<?xml version="1.0"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:r><w:t>IN THE HIGH COURT</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Petitioner</w:t><w:tab/><w:t>State</w:t><w:br/><w:t>Respondent</w:t></w:r></w:p>` +
+		`</w:body></w:document>`
+	got := run(t, extractDOCX, zipped(t, "word/document.xml", doc))
+	if want := "IN THE HIGH COURT\nPetitioner State\nRespondent"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := extractDOCX(context.Background(), zipped(t, "other.xml", doc), 0); err == nil {
+		t.Errorf("package without word/document.xml accepted")
+	}
+}
+
+func TestExtractODT(t *testing.T) {
+	doc := `<?xml version="1.0"?>` +
+		`<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">` +
+		`<office:body><office:text>` +
+		`<text:h>Judgment</text:h>` +
+		`<text:p>Bail<text:s/>granted<text:line-break/>on conditions</text:p>` +
+		`</office:text></office:body></office:document-content>`
+	got := run(t, extractODT, zipped(t, "content.xml", doc))
+	if want := "Judgment\nBail granted\non conditions"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExtractHTML(t *testing.T) {
+	page := `<html><head><title>Order</title><style>p{color:red}</style><script>var x = 1;</script></head>` +
+		`<body><h1>Writ   Petition</h1><p>Allowed with <b>costs</b>.</p><ul><li>one</li><li>two</li></ul></body></html>`
+	got := run(t, extractHTML, bytes.NewReader([]byte(page)))
+	if want := "Order\nWrit Petition\nAllowed with costs.\none\ntwo"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExtractRTF(t *testing.T) {
+	doc := `{\rtf1\ansi\deff0{\fonttbl{\f0 Times New Roman;}}{\colortbl;\red0\green0\blue0;}` +
+		`{\*\generator Writer;}\pard\f0 Caf\'e9 owner\par ` +
+		`Sec.\tab 302 \{IPC\}\line ` +
+		`na\u239?ve \\ done}`
+	got := run(t, extractRTF, bytes.NewReader([]byte(doc)))
+	if want := "Café owner\nSec. 302 {IPC}\nnaïve \\ done"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	got := run(t, extractText, bytes.NewReader([]byte("  first   line \r\nsecond\xff line\n\n")))
+	if want := "first line\nsecond line"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// named is an extractor that only tells which registration was found
+type named string
+
+func (n named) Extract(ctx context.Context, src Source, size int64) (Text, error) {
+	return Text{Pages: []string{string(n)}}, nil
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r := NewRegistry()
+	r.Register("application/pdf", named("pdf"), ".pdf")
+	r.Register("text/plain", named("text"), ".txt", ".TEXT")
+
+	cases := []struct {
+		mime, file string
+		want       string // "" for no extractor
+	}{
+		{"application/pdf", "scan.bin", "pdf"},              // declared type wins over the extension
+		{"text/plain; charset=utf-8", "notes", "text"},      // parameters are ignored
+		{"application/octet-stream", "judgment.PDF", "pdf"}, // generic type falls back to the extension
+		{"", "notes.text", "text"},                          // missing type too
+		{"not a / type", "a.txt", "text"},                   // and an unparsable one
+		{"application/zip", "archive.zip", ""},
+		{"", "no-extension", ""},
+	}
+	for _, tc := range cases {
+		e, ok := r.Lookup(tc.mime, tc.file)
+		got := ""
+		if ok {
+			got = string(e.(named))
+		}
+		if got != tc.want {
+			t.Errorf("Lookup(%q, %q) = %q, want %q", tc.mime, tc.file, got, tc.want)
+		}
+	}
+
+	if _, err := r.Extract(context.Background(), "application/zip", "a.zip", bytes.NewReader(nil), 0); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("got %v, want ErrUnsupported", err)
+	}
+}
+
+func TestDefaultRegistryUsesExtensions(t *testing.T) {
+	r := Default(nil, zap.NewNop())
+	src := zipped(t, "word/document.xml", `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>Order</w:t></w:r></w:p></w:body></w:document>`)
+	text, err := r.Extract(context.Background(), "application/octet-stream", "order.docx", src, src.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text.String() != "Order" {
+		t.Errorf("got %q", text.String())
+	}
+}
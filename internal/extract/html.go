@@ -0,0 +1,84 @@
+// internal/extract/html.go
+package extract
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements end a line in the extracted text
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "table": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"section": true, "article": true, "blockquote": true, "pre": true, "title": true,
+}
+
+// extractHTML returns the visible text, skipping scripts and styles
+func extractHTML(ctx context.Context, src Source, size int64) (string, error) {
+	doc, err := html.Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return htmlText(doc), nil
+}
+
+// htmlText collects the visible text below n, one line per block element
+func htmlText(n *html.Node) string {
+	var out strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			out.WriteString(n.Data)
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template":
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == html.ElementNode && blockElements[n.Data] {
+			out.WriteString("\n")
+		}
+	}
+	walk(n)
+	return normalizeLines(out.String())
+}
+
+// htmlPages splits Tika's XHTML output on its <div class="page"> elements;
+// documents without them come back as a single page
+func htmlPages(doc *html.Node) []string {
+	var pages []string
+	var find func(n *html.Node)
+	find = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "div" && hasClass(n, "page") {
+			pages = append(pages, htmlText(n))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			find(c)
+		}
+	}
+	find(doc)
+	if len(pages) == 0 {
+		return []string{htmlText(doc)}
+	}
+	return pages
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, a := range n.Attr {
+		if a.Key == "class" {
+			for _, c := range strings.Fields(a.Val) {
+				if c == class {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
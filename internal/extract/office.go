@@ -0,0 +1,99 @@
+// internal/extract/office.go
+package extract
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// extractDOCX reads word/document.xml from the OOXML package
+func extractDOCX(ctx context.Context, src Source, size int64) (string, error) {
+	return zippedXMLText(src, size, "word/document.xml", docxElement)
+}
+
+// extractODT reads content.xml from the OpenDocument package
+func extractODT(ctx context.Context, src Source, size int64) (string, error) {
+	return zippedXMLText(src, size, "content.xml", odtElement)
+}
+
+// docxElement maps WordprocessingML elements to the text they stand for. Empty
+// elements such as <w:br/> arrive as a start and an end tag, so they count once.
+func docxElement(name xml.Name, start bool) string {
+	switch name.Local {
+	case "tab":
+		if start {
+			return "\t"
+		}
+	case "br", "cr":
+		if start {
+			return "\n"
+		}
+	case "p":
+		if !start {
+			return "\n"
+		}
+	}
+	return ""
+}
+
+// odtElement maps OpenDocument text elements to the text they stand for
+func odtElement(name xml.Name, start bool) string {
+	switch name.Local {
+	case "s":
+		if start {
+			return " "
+		}
+	case "tab":
+		if start {
+			return "\t"
+		}
+	case "line-break":
+		if start {
+			return "\n"
+		}
+	case "p", "h":
+		if !start {
+			return "\n"
+		}
+	}
+	return ""
+}
+
+// zippedXMLText collects the character data of one XML member of a zip package.
+// element returns text to emit for start (start=true) and end tags.
+func zippedXMLText(src Source, size int64, member string, element func(name xml.Name, start bool) string) (string, error) {
+	zr, err := zip.NewReader(src, size)
+	if err != nil {
+		return "", err
+	}
+	f, err := zr.Open(member)
+	if err != nil {
+		return "", fmt.Errorf("open %s: %w", member, err)
+	}
+	defer f.Close()
+
+	var out strings.Builder
+	dec := xml.NewDecoder(f)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			out.WriteString(element(t.Name, true))
+		case xml.EndElement:
+			out.WriteString(element(t.Name, false))
+		case xml.CharData:
+			out.Write(t)
+		}
+	}
+	return normalizeLines(out.String()), nil
+}
@@ -0,0 +1,71 @@
+// internal/extract/pdf.go
+package extract
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+	"go.uber.org/zap"
+)
+
+// minDigitalText is the amount of text below which a PDF is treated as scanned
+const minDigitalText = 10
+
+// PDF reads the text layer page by page and falls back to Tika OCR for scanned documents
+func PDF(tika *Tika, logger *zap.Logger) Extractor {
+	return pdfExtractor{tika: tika, logger: logger}
+}
+
+type pdfExtractor struct {
+	tika   *Tika
+	logger *zap.Logger
+}
+
+func (p pdfExtractor) Extract(ctx context.Context, src Source, size int64) (Text, error) {
+	text := extractWithLedongthuc(src, size, p.logger)
+	if len(strings.TrimSpace(text.String())) >= minDigitalText {
+		return text, nil
+	}
+	p.logger.Info("Digital text extraction empty, falling back to Tika OCR")
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return text, err
+	}
+	ocr, err := p.tika.Extract(ctx, src, size)
+	if err != nil && strings.TrimSpace(text.String()) != "" {
+		// keep the little digital text we have rather than failing the file
+		p.logger.Warn("Tika fallback failed, keeping digital text", zap.Error(err))
+		return text, nil
+	}
+	return ocr, err
+}
+
+// extractWithLedongthuc returns one entry per page, empty for pages without a text layer
+func extractWithLedongthuc(r io.ReaderAt, size int64, logger *zap.Logger) Text {
+	logger.Info("Starting digital extraction", zap.Int64("size", size))
+	pr, err := pdf.NewReader(r, size)
+	if err != nil {
+		logger.Error("Failed to open PDF", zap.Error(err))
+		return Text{}
+	}
+
+	pages := make([]string, pr.NumPage())
+	length := 0
+	for i := 1; i <= pr.NumPage(); i++ {
+		page := pr.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			logger.Warn("Failed page extraction", zap.Int("page", i), zap.Error(err))
+			continue
+		}
+		pages[i-1] = normalizeLines(text)
+		length += len(pages[i-1])
+	}
+
+	logger.Info("Digital extraction completed", zap.Int("pages", len(pages)), zap.Int("length", length))
+	return Text{Pages: pages}
+}
@@ -0,0 +1,124 @@
+// internal/extract/rtf.go
+package extract
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// rtfSkipDestinations hold metadata rather than document text
+var rtfSkipDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"header": true, "footer": true, "listtable": true, "listoverridetable": true,
+	"rsidtbl": true, "generator": true, "themedata": true, "datastore": true, "latentstyles": true,
+}
+
+// extractRTF strips RTF control words and groups, keeping paragraph breaks and
+// decoding \'hh and \uN escapes
+func extractRTF(ctx context.Context, src Source, size int64) (string, error) {
+	b, err := io.ReadAll(src)
+	if err != nil {
+		return "", err
+	}
+	data := string(b)
+
+	var out strings.Builder
+	type group struct{ skip bool }
+	stack := []group{{}}
+	skipping := func() bool { return stack[len(stack)-1].skip }
+	ucSkip := 0 // characters to drop after a \uN escape
+
+	for i := 0; i < len(data); i++ {
+		ch := data[i]
+		switch ch {
+		case '{':
+			stack = append(stack, group{skip: skipping()})
+		case '}':
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case '\\':
+			if i+1 >= len(data) {
+				break
+			}
+			next := data[i+1]
+			switch {
+			case next == '\\' || next == '{' || next == '}':
+				if !skipping() {
+					out.WriteByte(next)
+				}
+				i++
+			case next == '\'':
+				if i+3 < len(data) {
+					if v, err := strconv.ParseUint(data[i+2:i+4], 16, 8); err == nil && !skipping() {
+						if ucSkip > 0 {
+							ucSkip--
+						} else {
+							out.WriteRune(rune(v)) // assumes a Latin-1 code page
+						}
+					}
+				}
+				i += 3
+			case next == '*':
+				stack[len(stack)-1].skip = true
+				i++
+			case unicode.IsLetter(rune(next)):
+				j := i + 1
+				for j < len(data) && unicode.IsLetter(rune(data[j])) {
+					j++
+				}
+				word := data[i+1 : j]
+				k := j
+				if k < len(data) && (data[k] == '-' || unicode.IsDigit(rune(data[k]))) {
+					k++
+					for k < len(data) && unicode.IsDigit(rune(data[k])) {
+						k++
+					}
+				}
+				param := data[j:k]
+				if k < len(data) && data[k] == ' ' {
+					k++ // the delimiting space belongs to the control word
+				}
+				i = k - 1
+
+				if rtfSkipDestinations[word] {
+					stack[len(stack)-1].skip = true
+					continue
+				}
+				if skipping() {
+					continue
+				}
+				switch word {
+				case "par", "line", "sect", "page", "row":
+					out.WriteByte('\n')
+				case "tab", "cell":
+					out.WriteByte('\t')
+				case "u":
+					if n, err := strconv.Atoi(param); err == nil {
+						if n < 0 {
+							n += 65536
+						}
+						out.WriteRune(rune(n))
+						ucSkip = 1
+					}
+				}
+			default:
+				i++ // control symbol such as \~ or \-
+			}
+		case '\r', '\n':
+		default:
+			if skipping() {
+				continue
+			}
+			if ucSkip > 0 {
+				ucSkip--
+				continue
+			}
+			out.WriteByte(ch)
+		}
+	}
+	return normalizeLines(out.String()), nil
+}
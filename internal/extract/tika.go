@@ -0,0 +1,146 @@
+// internal/extract/tika.go
+package extract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/html"
+
+	"github.com/mohan2020coder/mSpace/internal/config"
+)
+
+var (
+	// ErrTikaUnavailable means Tika is down or the circuit breaker is open; worth retrying later
+	ErrTikaUnavailable = errors.New("tika unavailable")
+	// ErrTooLarge means the file exceeds tika.max_file_size
+	ErrTooLarge = errors.New("file too large for text extraction")
+	// ErrUnprocessable means Tika could not parse the document (corrupt, encrypted or unsupported)
+	ErrUnprocessable = errors.New("document could not be parsed")
+)
+
+// Tika sends documents to an Apache Tika server, which OCRs images and scanned pages
+type Tika struct {
+	URL         string // server base URL, e.g. http://localhost:9998
+	OCRLanguage string
+	MaxFileSize int64
+	Client      *http.Client
+	Logger      *zap.Logger
+
+	breaker *breaker
+}
+
+func NewTika(cfg config.TikaCfg, logger *zap.Logger) *Tika {
+	return &Tika{
+		URL:         strings.TrimSuffix(cfg.URL, "/"),
+		OCRLanguage: cfg.OCRLanguage,
+		MaxFileSize: cfg.MaxFileSize,
+		Client:      &http.Client{Timeout: cfg.Timeout},
+		Logger:      logger,
+		breaker:     newBreaker(cfg.FailureThreshold, cfg.Cooldown),
+	}
+}
+
+// Extract returns the text Tika finds in the document, split on the page divs of its XHTML output
+func (t *Tika) Extract(ctx context.Context, src Source, size int64) (Text, error) {
+	if t.MaxFileSize > 0 && size > t.MaxFileSize {
+		return Text{}, fmt.Errorf("%w: %d bytes, limit %d", ErrTooLarge, size, t.MaxFileSize)
+	}
+
+	// Tika endpoint expects PUT; text/html keeps page boundaries that text/plain loses
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, t.URL+"/tika", src)
+	if err != nil {
+		return Text{}, err
+	}
+	req.ContentLength = size
+	req.Header.Set("Accept", "text/html")
+	if t.OCRLanguage != "" {
+		req.Header.Set("X-Tika-OCRLanguage", t.OCRLanguage)
+	}
+
+	// From here every path settles the breaker, or a half-open probe would never end
+	if !t.breaker.allow() {
+		return Text{}, fmt.Errorf("%w: circuit open", ErrTikaUnavailable)
+	}
+	t.Logger.Info("Starting Tika extraction", zap.Int64("size", size))
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		t.breaker.failure()
+		t.Logger.Error("Tika request failed", zap.Error(err))
+		return Text{}, fmt.Errorf("%w: %v", ErrTikaUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+	case http.StatusRequestEntityTooLarge:
+		// Tika is healthy, the document is the problem
+		t.breaker.success()
+		return Text{}, fmt.Errorf("%w: tika returned %s", ErrTooLarge, resp.Status)
+	case http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		// Tika could not parse this document
+		t.breaker.success()
+		return Text{}, fmt.Errorf("%w: tika returned %s", ErrUnprocessable, resp.Status)
+	default:
+		// 5xx, or a 4xx such as 404 from a wrong tika.url or 401/403 from a proxy:
+		// nothing is wrong with the document, so it is retried once Tika is reachable
+		t.breaker.failure()
+		return Text{}, fmt.Errorf("%w: tika returned %s", ErrTikaUnavailable, resp.Status)
+	}
+
+	doc, err := html.Parse(resp.Body)
+	if err != nil {
+		t.breaker.failure()
+		return Text{}, fmt.Errorf("%w: reading response: %v", ErrTikaUnavailable, err)
+	}
+	t.breaker.success()
+	text := Text{Pages: htmlPages(doc)}
+	length := len(strings.TrimSpace(text.String()))
+	t.Logger.Info("Tika extraction completed", zap.Int("pages", len(text.Pages)), zap.Int("length", length))
+
+	if length == 0 {
+		t.Logger.Warn("Tika returned empty text, check Tesseract installation")
+	}
+	return text, nil
+}
+
+// TikaHealth is the result of a health probe
+type TikaHealth struct {
+	OK      bool   `json:"ok"`
+	Version string `json:"version,omitempty"`
+	Circuit string `json:"circuit"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency"`
+}
+
+// Health asks Tika for its version; it bypasses the breaker so it can be used to diagnose it
+func (t *Tika) Health(ctx context.Context) TikaHealth {
+	start := time.Now()
+	h := TikaHealth{Circuit: t.breaker.state()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.URL+"/version", nil)
+	if err == nil {
+		var resp *http.Response
+		resp, err = t.Client.Do(req)
+		if err == nil {
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			if resp.StatusCode == http.StatusOK {
+				h.OK, h.Version = true, strings.TrimSpace(string(b))
+			} else {
+				err = fmt.Errorf("tika returned %s", resp.Status)
+			}
+		}
+	}
+	if err != nil {
+		h.Error = err.Error()
+	}
+	h.Latency = time.Since(start).String()
+	return h
+}
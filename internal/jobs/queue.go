@@ -0,0 +1,217 @@
+// internal/jobs/queue.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mohan2020coder/mSpace/internal/config"
+	"github.com/mohan2020coder/mSpace/internal/models"
+)
+
+// Handler runs one job; returning an error schedules a retry unless it is Permanent
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Queue is a Postgres backed job queue. Workers claim jobs with
+// SELECT ... FOR UPDATE SKIP LOCKED so several API instances can share it.
+type Queue struct {
+	DB     *gorm.DB
+	Logger *zap.Logger
+	Cfg    config.JobsCfg
+
+	// OnFailure, when set, is called once a job is marked FAILED
+	OnFailure func(job *models.Job)
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewQueue(db *gorm.DB, logger *zap.Logger, cfg config.JobsCfg) *Queue {
+	return &Queue{DB: db, Logger: logger, Cfg: cfg, handlers: map[string]Handler{}}
+}
+
+// Register sets the handler for a job kind
+func (q *Queue) Register(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = h
+}
+
+func (q *Queue) handler(kind string) (Handler, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	h, ok := q.handlers[kind]
+	return h, ok
+}
+
+// permanentError marks failures that retrying cannot fix
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job fails immediately instead of being retried
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+// Enqueue inserts a job using tx, so it commits together with the caller's changes
+func (q *Queue) Enqueue(tx *gorm.DB, kind string, itemID *uint, payload any) (*models.Job, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := models.Job{
+		Kind:        kind,
+		ItemID:      itemID,
+		Payload:     models.JSONText(b),
+		Status:      models.JobQueued,
+		RunAt:       time.Now(),
+		MaxAttempts: q.Cfg.MaxAttempts,
+	}
+	if err := tx.Create(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Run starts the workers and blocks until ctx is cancelled
+func (q *Queue) Run(ctx context.Context) {
+	host, _ := os.Hostname()
+	var wg sync.WaitGroup
+	for i := 0; i < q.Cfg.Workers; i++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			q.work(ctx, name)
+		}(fmt.Sprintf("%s/%d/%d", host, os.Getpid(), i))
+	}
+	wg.Wait()
+}
+
+func (q *Queue) work(ctx context.Context, worker string) {
+	for {
+		job, err := q.claim(worker)
+		if err != nil {
+			q.Logger.Error("claim job failed", zap.Error(err))
+		}
+		if job != nil {
+			q.execute(ctx, job)
+			continue
+		}
+		if err := q.requeueStale(); err != nil {
+			q.Logger.Error("requeue stale jobs failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.Cfg.PollInterval):
+		}
+	}
+}
+
+// claim locks the oldest due job and marks it RUNNING; it returns nil when none is due
+func (q *Queue) claim(worker string) (*models.Job, error) {
+	var job models.Job
+	err := q.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", models.JobQueued, time.Now()).
+			Order("run_at, id").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		job.Status, job.Attempts, job.LockedAt, job.LockedBy = models.JobRunning, job.Attempts+1, &now, worker
+		return tx.Model(&job).Select("status", "attempts", "locked_at", "locked_by").Updates(&job).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (q *Queue) execute(ctx context.Context, job *models.Job) {
+	h, ok := q.handler(job.Kind)
+	var err error
+	if !ok {
+		err = Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	} else {
+		err = runSafely(ctx, h, job)
+	}
+	q.finish(job, err)
+}
+
+// runSafely turns a handler panic into a job error
+func runSafely(ctx context.Context, h Handler, job *models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return h(ctx, job)
+}
+
+// finish records the outcome; failed attempts are retried with exponential backoff
+func (q *Queue) finish(job *models.Job, err error) {
+	now := time.Now()
+	updates := map[string]any{"locked_at": nil, "locked_by": ""}
+	var permanent permanentError
+	switch {
+	case err == nil:
+		updates["status"], updates["finished_at"], updates["last_error"] = models.JobSucceeded, now, ""
+		job.Status = models.JobSucceeded
+	case errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts:
+		updates["status"], updates["finished_at"], updates["last_error"] = models.JobFailed, now, err.Error()
+		job.Status, job.LastError = models.JobFailed, err.Error()
+	default:
+		delay := q.Cfg.Backoff << (job.Attempts - 1)
+		updates["status"], updates["run_at"], updates["last_error"] = models.JobQueued, now.Add(delay), err.Error()
+		job.Status, job.LastError = models.JobQueued, err.Error()
+	}
+
+	if dbErr := q.DB.Model(job).Updates(updates).Error; dbErr != nil {
+		q.Logger.Error("update job failed", zap.Uint("job", job.ID), zap.Error(dbErr))
+		return
+	}
+
+	switch job.Status {
+	case models.JobSucceeded:
+		q.Logger.Info("job done", zap.Uint("job", job.ID), zap.String("kind", job.Kind))
+	case models.JobFailed:
+		q.Logger.Error("job failed", zap.Uint("job", job.ID), zap.String("kind", job.Kind), zap.Int("attempts", job.Attempts), zap.Error(err))
+		if q.OnFailure != nil {
+			q.OnFailure(job)
+		}
+	default:
+		q.Logger.Warn("job will be retried", zap.Uint("job", job.ID), zap.String("kind", job.Kind), zap.Int("attempts", job.Attempts), zap.Error(err))
+	}
+}
+
+// requeueStale puts jobs back whose worker died while running them
+func (q *Queue) requeueStale() error {
+	return q.DB.Model(&models.Job{}).
+		Where("status = ? AND locked_at < ?", models.JobRunning, time.Now().Add(-q.Cfg.LockTimeout)).
+		Updates(map[string]any{"status": models.JobQueued, "locked_at": nil, "locked_by": "", "run_at": time.Now()}).Error
+}
+
+// Pending reports whether the item has jobs of kind that are queued or running, ignoring exceptID
+func (q *Queue) Pending(kind string, itemID, exceptID uint) (bool, error) {
+	var count int64
+	err := q.DB.Model(&models.Job{}).
+		Where("kind = ? AND item_id = ? AND id <> ? AND status IN ?", kind, itemID, exceptID, []string{models.JobQueued, models.JobRunning}).
+		Count(&count).Error
+	return count > 0, err
+}
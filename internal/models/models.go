@@ -1,6 +1,11 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Community groups collections
 type Community struct {
@@ -19,23 +24,256 @@ type Collection struct {
 	Items       []Item `json:"items"`
 }
 
+// Item visibility values
+const (
+	VisibilityPublic  = "PUBLIC"
+	VisibilityPrivate = "PRIVATE"
+)
+
+// Item processing states, independent of the workflow status
+const (
+	ProcessingReady   = "READY"      // all uploaded files are extracted and indexed
+	ProcessingPending = "PROCESSING" // ingestion jobs are queued or running
+	ProcessingFailed  = "FAILED"     // an ingestion job gave up, see ProcessingError
+)
+
 // Item with versioning and workflow
 type Item struct {
 	gorm.Model
 	Title        string     `json:"title" gorm:"type:text"`
 	Author       string     `json:"author" gorm:"type:text"`
 	Abstract     string     `json:"abstract" gorm:"type:text"`
-	Status       string     `json:"status" gorm:"index"` // DRAFT/SUBMITTED/PUBLISHED/REJECTED
+	Status       string     `json:"status" gorm:"index"` // see workflow package for states
 	FileURL      string     `json:"file_url" gorm:"type:text"`
 	Version      int        `json:"version"`
 	CollectionID uint       `json:"collection_id" gorm:"index"`
 	Metadata     []Metadata `json:"metadata"`
-	Visibility   string     `json:"visibility" gorm:"index"` // PUBLIC/PRIVATE
+	Visibility   string     `json:"visibility" gorm:"index"`              // PUBLIC/PRIVATE
+	EmbargoUntil *time.Time `json:"embargo_until,omitempty" gorm:"index"` // PRIVATE item becomes PUBLIC after this date
+	SubmitterID  uint       `json:"submitter_id" gorm:"index"`
 	FullText     string     `json:"full_text" gorm:"type:text"`
 
+	WorkflowStep  int    `json:"workflow_step"`                   // current reviewer step while IN_REVIEW, 1-based
+	ReviewComment string `json:"review_comment" gorm:"type:text"` // last reviewer comment
+
+	Processing      string `json:"processing" gorm:"default:READY;index"` // READY/PROCESSING/FAILED
+	ProcessingError string `json:"processing_error,omitempty" gorm:"type:text"`
+
 	LegalJSON string `gorm:"type:json"`
 }
 
+// User is an account that can authenticate against the API
+type User struct {
+	gorm.Model
+	Username     string  `json:"username" gorm:"type:text;uniqueIndex"`
+	PasswordHash string  `json:"-" gorm:"type:text"`
+	IsAdmin      bool    `json:"is_admin"` // site-wide administrator
+	Groups       []Group `json:"groups,omitempty" gorm:"many2many:user_groups"`
+}
+
+// Group bundles users so policies can be granted to many at once
+type Group struct {
+	gorm.Model
+	Name        string `json:"name" gorm:"type:text;uniqueIndex"`
+	Description string `json:"description" gorm:"type:text"`
+	Users       []User `json:"users,omitempty" gorm:"many2many:user_groups"`
+}
+
+// Resource types a policy can be scoped to
+const (
+	ResourceCommunity  = "COMMUNITY"
+	ResourceCollection = "COLLECTION"
+)
+
+// Policy actions, each one implies the ones before it
+const (
+	ActionRead   = "READ"
+	ActionSubmit = "SUBMIT"
+	ActionReview = "REVIEW"
+	ActionAdmin  = "ADMIN"
+)
+
+// ResourcePolicy grants an action on a community or collection to a user or a group
+type ResourcePolicy struct {
+	gorm.Model
+	ResourceType string `json:"resource_type" gorm:"index:idx_policy_resource"` // COMMUNITY/COLLECTION
+	ResourceID   uint   `json:"resource_id" gorm:"index:idx_policy_resource"`
+	Action       string `json:"action" gorm:"index"` // READ/SUBMIT/REVIEW/ADMIN
+	UserID       *uint  `json:"user_id,omitempty" gorm:"index"`
+	GroupID      *uint  `json:"group_id,omitempty" gorm:"index"`
+}
+
+// Bundles group the files attached to an item
+const (
+	BundleOriginal  = "ORIGINAL"  // deposited files
+	BundleText      = "TEXT"      // extracted text derived from ORIGINAL files
+	BundleThumbnail = "THUMBNAIL" // preview images
+	BundleLicense   = "LICENSE"   // deposit licenses
+)
+
+// Bitstream is one stored version of a file in one of the item's bundles.
+// A file is identified by (ItemID, Bundle, Sequence); each upload adds a version.
+type Bitstream struct {
+	gorm.Model
+	ItemID      uint   `json:"item_id" gorm:"uniqueIndex:idx_bitstream_file_version"`
+	Bundle      string `json:"bundle" gorm:"default:ORIGINAL;uniqueIndex:idx_bitstream_file_version"`
+	Sequence    int    `json:"sequence" gorm:"default:1;uniqueIndex:idx_bitstream_file_version"` // order within the bundle, 1-based
+	Version     int    `json:"version" gorm:"uniqueIndex:idx_bitstream_file_version"`
+	Current     bool   `json:"current" gorm:"index"` // the active version of this file
+	ObjectName  string `json:"object_name" gorm:"type:text"`
+	FileName    string `json:"file_name" gorm:"type:text"`
+	Size        int64  `json:"size"`
+	Checksum    string `json:"checksum"`     // hex SHA-256 of the content
+	ChecksumMD5 string `json:"checksum_md5"` // hex MD5 of the content
+	MimeType    string `json:"mime_type"`
+	UploaderID  uint   `json:"uploader_id" gorm:"index"`
+	FullText    string `json:"-" gorm:"type:text"`
+	LegalJSON   string `json:"-" gorm:"type:json"`
+
+	ExtractionError string `json:"extraction_error,omitempty" gorm:"type:text"` // why text extraction failed, empty on success
+
+	FixityStatus    string     `json:"fixity_status" gorm:"default:UNCHECKED;index"`
+	FixityCheckedAt *time.Time `json:"fixity_checked_at,omitempty" gorm:"index"`
+	FixityDetail    string     `json:"fixity_detail,omitempty" gorm:"type:text"` // what the last failed check found
+}
+
+// BitstreamPage is the extracted text of one page of a bitstream; pages without text are not stored
+type BitstreamPage struct {
+	ID          uint   `json:"-" gorm:"primaryKey"`
+	BitstreamID uint   `json:"bitstream_id" gorm:"uniqueIndex:idx_bitstream_page"`
+	Page        int    `json:"page" gorm:"uniqueIndex:idx_bitstream_page"` // 1-based
+	Text        string `json:"text" gorm:"type:text"`
+}
+
+// ItemChunk is a passage of an item with its embedding for semantic search. Chunks
+// come from the item's metadata (Sequence and Page 0) and from the pages of its
+// current original files.
+type ItemChunk struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ItemID    uint      `json:"item_id" gorm:"index"`
+	Sequence  int       `json:"sequence"` // file sequence, 0 for metadata
+	Page      int       `json:"page"`     // 1-based page, 0 for metadata
+	Chunk     int       `json:"chunk"`    // position within the page
+	Text      string    `json:"text" gorm:"type:text"`
+	Model     string    `json:"model" gorm:"index"`
+	Embedding []byte    `json:"-"` // normalized little-endian float32s
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Fixity check outcomes
+const (
+	FixityUnchecked = "UNCHECKED"
+	FixityOK        = "OK"
+	FixityMismatch  = "MISMATCH" // stored content no longer matches the recorded checksums
+	FixityMissing   = "MISSING"  // object is gone from storage
+	FixityError     = "ERROR"    // storage could not be read
+)
+
+// Upload session states
+const (
+	UploadActive    = "ACTIVE"
+	UploadCompleted = "COMPLETED"
+	UploadAborted   = "ABORTED"
+	UploadExpired   = "EXPIRED"
+	UploadFailed    = "FAILED" // assembled but not attached; the parts are gone, so start a new upload
+)
+
+// UploadSession is a resumable multipart upload of one file into an item bundle
+type UploadSession struct {
+	gorm.Model
+	ItemID          uint      `json:"item_id" gorm:"index"`
+	Bundle          string    `json:"bundle"`
+	Sequence        int       `json:"sequence"`
+	ObjectName      string    `json:"-" gorm:"type:text"`
+	StorageUploadID string    `json:"-" gorm:"type:text"`
+	FileName        string    `json:"file_name" gorm:"type:text"`
+	MimeType        string    `json:"mime_type"`
+	Size            int64     `json:"size"` // declared total size in bytes
+	PartSize        int64     `json:"part_size"`
+	UploaderID      uint      `json:"uploader_id" gorm:"index"`
+	Status          string    `json:"status" gorm:"index"`
+	ExpiresAt       time.Time `json:"expires_at" gorm:"index"` // extended by every received part
+	BitstreamID     *uint     `json:"bitstream_id,omitempty"`
+}
+
+// UploadPart is a received chunk of an upload session; re-sending a part replaces it
+type UploadPart struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	SessionID uint      `json:"-" gorm:"uniqueIndex:idx_upload_part"`
+	Number    int       `json:"number" gorm:"uniqueIndex:idx_upload_part"`
+	ETag      string    `json:"etag"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Job states
+const (
+	JobQueued    = "QUEUED"
+	JobRunning   = "RUNNING"
+	JobSucceeded = "SUCCEEDED"
+	JobFailed    = "FAILED" // attempts exhausted or permanent error
+)
+
+// Job is one unit of background work in the Postgres backed queue (see jobs package)
+type Job struct {
+	gorm.Model
+	Kind        string     `json:"kind" gorm:"index"`
+	ItemID      *uint      `json:"item_id,omitempty" gorm:"index"`
+	Payload     JSONText   `json:"payload" gorm:"type:json"`
+	Status      string     `json:"status" gorm:"index:idx_job_ready"`
+	RunAt       time.Time  `json:"run_at" gorm:"index:idx_job_ready"` // not picked up before this time, pushed back on retry
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LockedBy    string     `json:"locked_by,omitempty"`
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// WorkflowStep is one ordered reviewer stage of a collection's review workflow
+type WorkflowStep struct {
+	gorm.Model
+	CollectionID uint   `json:"collection_id" gorm:"index"`
+	Position     int    `json:"position"` // 1-based order
+	Name         string `json:"name" gorm:"type:text"`
+	GroupID      *uint  `json:"group_id,omitempty"` // reviewers for this step; nil means anyone with REVIEW
+}
+
+// ErrAppendOnly is returned when something tries to modify a recorded ItemEvent
+var ErrAppendOnly = errors.New("item events are append-only")
+
+// JSONText is a JSON document stored as text and emitted verbatim in API responses
+type JSONText string
+
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+// ItemEvent is one entry of an item's provenance trail; rows are never updated or deleted
+type ItemEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ItemID    uint      `json:"item_id" gorm:"index"`
+	ActorID   uint      `json:"actor_id" gorm:"index"`  // 0 for system actions
+	Actor     string    `json:"actor" gorm:"type:text"` // username at the time of the change
+	Action    string    `json:"action" gorm:"index"`    // CREATE/UPDATE/UPLOAD/SUBMIT/PUBLISH/...
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	Changes   JSONText  `json:"changes" gorm:"type:json"` // {"field": {"old": ..., "new": ...}}
+	Comment   string    `json:"comment" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+func (e *ItemEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAppendOnly
+}
+
+func (e *ItemEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrAppendOnly
+}
+
 // Metadata for arbitrary fields
 type Metadata struct {
 	gorm.Model
@@ -0,0 +1,596 @@
+// internal/search/advanced.go
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// SyntaxError reports a mistake in an advanced query; Pos is the byte offset it was found at
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("query syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// maxAdvancedQueryLength bounds the input so a single request cannot build a huge query
+const maxAdvancedQueryLength = 2000
+
+// fieldKind decides how values of a field are matched
+type fieldKind int
+
+const (
+	kindText    fieldKind = iota // analyzed; match, phrase, fuzzy and wildcard
+	kindKeyword                  // exact terms and wildcards
+	kindDate                     // ranges and single days
+	kindNumber                   // exact numbers
+)
+
+type advancedField struct {
+	paths []string
+	kind  fieldKind
+	upper bool // keyword values are stored upper case
+}
+
+// advancedFields maps the field names accepted in advanced queries to index fields
+var advancedFields = map[string]advancedField{
+	"title":       {paths: []string{"Title"}},
+	"author":      {paths: []string{"Author"}},
+	"abstract":    {paths: []string{"Abstract"}},
+	"text":        {paths: []string{"FullText", "Pages.Text"}},
+	"synopsis":    {paths: []string{"Synopsis"}},
+	"petitioner":  {paths: []string{"Petitioners"}},
+	"respondent":  {paths: []string{"Respondents"}},
+	"party":       {paths: []string{"Petitioners", "Respondents"}},
+	"event":       {paths: []string{"Events.Event"}},
+	"case":        {paths: []string{"CaseNumber"}, kind: kindKeyword, upper: true},
+	"status":      {paths: []string{"Status"}, kind: kindKeyword, upper: true},
+	"visibility":  {paths: []string{"Visibility"}, kind: kindKeyword, upper: true},
+	"event_date":  {paths: []string{"Events.Date"}, kind: kindDate},
+	"created":     {paths: []string{"CreatedAt"}, kind: kindDate},
+	"collection":  {paths: []string{"CollectionID"}, kind: kindNumber},
+	"petitioners": {paths: []string{"Petitioners"}},
+	"respondents": {paths: []string{"Respondents"}},
+	"fulltext":    {paths: []string{"FullText", "Pages.Text"}},
+	"case_number": {paths: []string{"CaseNumber"}, kind: kindKeyword, upper: true},
+}
+
+// anyField is used for terms without a field prefix
+var anyField = advancedField{paths: textFields}
+
+// ParseAdvanced parses the advanced query syntax:
+//
+//	title:"writ petition" AND petitioner:State -rejected
+//	respondent:kerela~1 OR case:CRL*
+//	event_date:[2019-01-01 TO 2019-12-31] NOT status:withdrawn
+//
+// Terms are combined with AND (the default), OR and NOT (or a leading -), and
+// grouped with parentheses. A field prefix applies to a term, phrase or group.
+// word~N matches with edit distance N (1 if omitted), * and ? are wildcards, and
+// date fields take ranges ([a TO b], >date, <=date) or a single day.
+func ParseAdvanced(input string) (query.Query, error) {
+	if len(input) > maxAdvancedQueryLength {
+		return nil, &SyntaxError{Pos: maxAdvancedQueryLength, Msg: fmt.Sprintf("query longer than %d characters", maxAdvancedQueryLength)}
+	}
+	tokens, err := lexAdvanced(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &advancedParser{tokens: tokens}
+	q, err := p.parseOr("")
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t)}
+	}
+	return q.build(), nil
+}
+
+// ---------------- Lexer ----------------
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokField // name followed by ':'
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokPhrase:
+		return fmt.Sprintf("phrase %q", t.text)
+	case tokField:
+		return fmt.Sprintf("field %q", t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func lexAdvanced(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			tokens = append(tokens, token{kind: bracketKind(c), text: string(c), pos: i})
+			i++
+		case c == '-' && (i == 0 || isSpaceOrOpen(input[i-1])):
+			tokens = append(tokens, token{kind: tokNot, text: "-", pos: i})
+			i++
+		case c == '"':
+			end := strings.IndexByte(input[i+1:], '"')
+			if end < 0 {
+				return nil, &SyntaxError{Pos: i, Msg: "unterminated phrase"}
+			}
+			text := input[i+1 : i+1+end]
+			if strings.TrimSpace(text) == "" {
+				return nil, &SyntaxError{Pos: i, Msg: "empty phrase"}
+			}
+			tokens = append(tokens, token{kind: tokPhrase, text: text, pos: i})
+			i += end + 2
+		default:
+			start := i
+			for i < len(input) && !strings.ContainsRune(" \t\n\r()[]\"", rune(input[i])) {
+				if input[i] == ':' && i > start && isFieldName(input[start:i]) {
+					break
+				}
+				i++
+			}
+			if i < len(input) && input[i] == ':' && i > start {
+				tokens = append(tokens, token{kind: tokField, text: strings.ToLower(input[start:i]), pos: start})
+				i++
+				continue
+			}
+			word := input[start:i]
+			kind := tokWord
+			switch word {
+			case "AND", "&&":
+				kind = tokAnd
+			case "OR", "||":
+				kind = tokOr
+			case "NOT":
+				kind = tokNot
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: start})
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(input)}), nil
+}
+
+func bracketKind(c byte) tokenKind {
+	switch c {
+	case '(':
+		return tokLParen
+	case ')':
+		return tokRParen
+	case '[':
+		return tokLBracket
+	}
+	return tokRBracket
+}
+
+func isSpaceOrOpen(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '('
+}
+
+func isFieldName(s string) bool {
+	for _, r := range s {
+		if r != '_' && !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ---------------- Parser ----------------
+
+// node is a parsed clause; negated clauses are collected as must-not by their parent
+type node struct {
+	q       query.Query
+	negated bool
+}
+
+// group is a conjunction (and) or disjunction of clauses
+type group struct {
+	and     bool
+	clauses []node
+}
+
+func (g group) build() query.Query {
+	var must, should, mustNot []query.Query
+	for _, c := range g.clauses {
+		switch {
+		case c.negated:
+			mustNot = append(mustNot, c.q)
+		case g.and:
+			must = append(must, c.q)
+		default:
+			should = append(should, c.q)
+		}
+	}
+	if len(mustNot) == 0 {
+		switch {
+		case len(must) == 1 && len(should) == 0:
+			return must[0]
+		case len(should) == 1 && len(must) == 0:
+			return should[0]
+		case g.and:
+			return bleve.NewConjunctionQuery(must...)
+		default:
+			return bleve.NewDisjunctionQuery(should...)
+		}
+	}
+	if g.and {
+		if len(must) == 0 {
+			must = []query.Query{bleve.NewMatchAllQuery()}
+		}
+		return booleanQuery(must, mustNot)
+	}
+	// "a OR NOT b" keeps documents matching a or lacking b
+	for _, q := range mustNot {
+		should = append(should, booleanQuery([]query.Query{bleve.NewMatchAllQuery()}, []query.Query{q}))
+	}
+	return bleve.NewDisjunctionQuery(should...)
+}
+
+func booleanQuery(must, mustNot []query.Query) query.Query {
+	q := bleve.NewBooleanQuery()
+	q.AddMust(must...)
+	q.AddMustNot(mustNot...)
+	return q
+}
+
+type advancedParser struct {
+	tokens []token
+	i      int
+	depth  int
+}
+
+const maxAdvancedDepth = 20
+
+func (p *advancedParser) peek() token { return p.tokens[p.i] }
+
+func (p *advancedParser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// parseOr: and-group (OR and-group)*
+func (p *advancedParser) parseOr(field string) (group, error) {
+	first, err := p.parseAnd(field)
+	if err != nil {
+		return group{}, err
+	}
+	if p.peek().kind != tokOr {
+		return first, nil
+	}
+	or := group{clauses: []node{{q: first.build()}}}
+	for p.peek().kind == tokOr {
+		p.next()
+		g, err := p.parseAnd(field)
+		if err != nil {
+			return group{}, err
+		}
+		// a lone negated term stays negated so the OR can expand it
+		if len(g.clauses) == 1 && g.clauses[0].negated {
+			or.clauses = append(or.clauses, g.clauses[0])
+			continue
+		}
+		or.clauses = append(or.clauses, node{q: g.build()})
+	}
+	return or, nil
+}
+
+// parseAnd: unary ((AND)? unary)*
+func (p *advancedParser) parseAnd(field string) (group, error) {
+	g := group{and: true}
+	for {
+		t := p.peek()
+		switch t.kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(g.clauses) == 0 {
+				return g, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected a term before %s", t)}
+			}
+			return g, nil
+		case tokAnd:
+			if len(g.clauses) == 0 {
+				return g, &SyntaxError{Pos: t.pos, Msg: "AND needs a term on its left"}
+			}
+			p.next()
+			if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokOr || k == tokAnd {
+				return g, &SyntaxError{Pos: p.peek().pos, Msg: "AND needs a term on its right"}
+			}
+		}
+		n, err := p.parseUnary(field)
+		if err != nil {
+			return g, err
+		}
+		g.clauses = append(g.clauses, n)
+	}
+}
+
+// parseUnary: (NOT|-)* primary
+func (p *advancedParser) parseUnary(field string) (node, error) {
+	negated := false
+	for p.peek().kind == tokNot {
+		p.next()
+		negated = !negated
+	}
+	q, err := p.parsePrimary(field)
+	return node{q: q, negated: negated}, err
+}
+
+// parsePrimary: '(' or-group ')' | field ':' primary | phrase | range | word
+func (p *advancedParser) parsePrimary(field string) (query.Query, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		p.depth++
+		if p.depth > maxAdvancedDepth {
+			return nil, &SyntaxError{Pos: t.pos, Msg: "too many nested groups"}
+		}
+		g, err := p.parseOr(field)
+		if err != nil {
+			return nil, err
+		}
+		if c := p.next(); c.kind != tokRParen {
+			return nil, &SyntaxError{Pos: c.pos, Msg: fmt.Sprintf("expected ) to close the group opened at %d, found %s", t.pos, c)}
+		}
+		p.depth--
+		return g.build(), nil
+	case tokField:
+		if field != "" {
+			return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("field %q inside field %q", t.text, field)}
+		}
+		if _, ok := advancedFields[t.text]; !ok {
+			return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unknown field %q, expected one of %s", t.text, advancedFieldNames())}
+		}
+		if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokAnd || k == tokOr {
+			return nil, &SyntaxError{Pos: p.peek().pos, Msg: fmt.Sprintf("missing value for field %q", t.text)}
+		}
+		return p.parsePrimary(t.text)
+	case tokLBracket:
+		return p.parseRange(field, t)
+	case tokPhrase:
+		return fieldQuery(field, t, phraseValue)
+	case tokWord:
+		return fieldQuery(field, t, wordValue)
+	}
+	return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t)}
+}
+
+// parseRange reads "[from TO to]"; * leaves an end open
+func (p *advancedParser) parseRange(field string, open token) (query.Query, error) {
+	from, to, closing := p.next(), p.next(), p.next()
+	if to.kind == tokWord && to.text == "TO" {
+		to, closing = closing, p.next()
+	} else {
+		return nil, &SyntaxError{Pos: to.pos, Msg: "expected [from TO to]"}
+	}
+	if from.kind != tokWord || to.kind != tokWord || closing.kind != tokRBracket {
+		return nil, &SyntaxError{Pos: open.pos, Msg: "expected [from TO to]"}
+	}
+	f, ok := advancedFields[field]
+	if !ok || (f.kind != kindDate && f.kind != kindNumber) {
+		return nil, &SyntaxError{Pos: open.pos, Msg: "ranges need a date or number field, e.g. event_date:[2019-01-01 TO 2019-12-31]"}
+	}
+	return rangeQuery(f, open.pos, from.text, to.text, true, true)
+}
+
+type valueKind int
+
+const (
+	wordValue valueKind = iota
+	phraseValue
+)
+
+// fieldQuery matches one word or phrase against the field, or all text fields when field is ""
+func fieldQuery(field string, t token, kind valueKind) (query.Query, error) {
+	f := anyField
+	if field != "" {
+		f = advancedFields[field]
+	}
+	var clauses []query.Query
+	for _, path := range f.paths {
+		q, err := valueQuery(f, path, t, kind)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, q)
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return bleve.NewDisjunctionQuery(clauses...), nil
+}
+
+func valueQuery(f advancedField, path string, t token, kind valueKind) (query.Query, error) {
+	text := t.text
+	switch f.kind {
+	case kindDate:
+		return dateValueQuery(f, t)
+	case kindNumber:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("%q is not a number", text)}
+		}
+		inclusive := true
+		q := bleve.NewNumericRangeInclusiveQuery(&n, &n, &inclusive, &inclusive)
+		q.SetField(path)
+		return q, nil
+	case kindKeyword:
+		if f.upper {
+			text = strings.ToUpper(text)
+		}
+		if kind == wordValue && strings.ContainsAny(text, "*?") {
+			return wildcardQuery(path, text, t.pos)
+		}
+		q := bleve.NewTermQuery(text)
+		q.SetField(path)
+		return q, nil
+	}
+
+	if kind == phraseValue {
+		q := bleve.NewMatchPhraseQuery(text)
+		q.SetField(path)
+		return q, nil
+	}
+	if i := strings.LastIndexByte(text, '~'); i > 0 {
+		fuzziness := 1
+		if i < len(text)-1 {
+			n, err := strconv.Atoi(text[i+1:])
+			if err != nil || n < 1 || n > 2 {
+				return nil, &SyntaxError{Pos: t.pos + i, Msg: "fuzziness must be ~1 or ~2"}
+			}
+			fuzziness = n
+		}
+		q := bleve.NewMatchQuery(text[:i])
+		q.SetField(path)
+		q.SetFuzziness(fuzziness)
+		return q, nil
+	}
+	if strings.ContainsAny(text, "*?") {
+		return wildcardQuery(path, strings.ToLower(text), t.pos)
+	}
+	q := bleve.NewMatchQuery(text)
+	q.SetField(path)
+	return q, nil
+}
+
+func wildcardQuery(path, pattern string, pos int) (query.Query, error) {
+	if strings.IndexAny(pattern, "*?") == 0 {
+		return nil, &SyntaxError{Pos: pos, Msg: "wildcards are not allowed at the start of a term"}
+	}
+	q := bleve.NewWildcardQuery(pattern)
+	q.SetField(path)
+	return q, nil
+}
+
+// dateValueQuery handles ">2019-01-01", "<=31/12/2019" and a single day "2019-03-05"
+func dateValueQuery(f advancedField, t token) (query.Query, error) {
+	text := t.text
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(text, op) {
+			continue
+		}
+		value := text[len(op):]
+		switch op {
+		case ">=":
+			return rangeQuery(f, t.pos, value, "*", true, true)
+		case ">":
+			return rangeQuery(f, t.pos, value, "*", false, true)
+		case "<=":
+			return rangeQuery(f, t.pos, "*", value, true, true)
+		default:
+			return rangeQuery(f, t.pos, "*", value, true, false)
+		}
+	}
+	day, err := parseQueryDate(text)
+	if err != nil {
+		return nil, &SyntaxError{Pos: t.pos, Msg: err.Error()}
+	}
+	end := day.Add(24*time.Hour - time.Nanosecond)
+	inclusive := true
+	q := bleve.NewDateRangeInclusiveQuery(day, end, &inclusive, &inclusive)
+	q.SetField(f.paths[0])
+	return q, nil
+}
+
+// rangeQuery builds a date or numeric range; "*" leaves an end open
+func rangeQuery(f advancedField, pos int, from, to string, fromInclusive, toInclusive bool) (query.Query, error) {
+	if from == "*" && to == "*" {
+		return nil, &SyntaxError{Pos: pos, Msg: "a range needs at least one end"}
+	}
+	if f.kind == kindNumber {
+		var lo, hi *float64
+		for _, v := range []struct {
+			s   string
+			dst **float64
+		}{{from, &lo}, {to, &hi}} {
+			if v.s == "*" {
+				continue
+			}
+			n, err := strconv.ParseFloat(v.s, 64)
+			if err != nil {
+				return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("%q is not a number", v.s)}
+			}
+			*v.dst = &n
+		}
+		q := bleve.NewNumericRangeInclusiveQuery(lo, hi, &fromInclusive, &toInclusive)
+		q.SetField(f.paths[0])
+		return q, nil
+	}
+
+	var start, end time.Time
+	var err error
+	if from != "*" {
+		if start, err = parseQueryDate(from); err != nil {
+			return nil, &SyntaxError{Pos: pos, Msg: err.Error()}
+		}
+		if !fromInclusive {
+			start, fromInclusive = start.Add(24*time.Hour), true // after the whole day
+		}
+	}
+	if to != "*" {
+		if end, err = parseQueryDate(to); err != nil {
+			return nil, &SyntaxError{Pos: pos, Msg: err.Error()}
+		}
+		if toInclusive {
+			end = end.Add(24*time.Hour - time.Nanosecond) // a bare day includes all of it
+		}
+	}
+	q := bleve.NewDateRangeInclusiveQuery(start, end, &fromInclusive, &toInclusive)
+	q.SetField(f.paths[0])
+	return q, nil
+}
+
+func parseQueryDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "02/01/2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date, use YYYY-MM-DD or DD/MM/YYYY", s)
+}
+
+func advancedFieldNames() string {
+	names := make([]string, 0, len(advancedFields))
+	for name := range advancedFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
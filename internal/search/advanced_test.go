@@ -0,0 +1,65 @@
+// internal/search/advanced_test.go
+package search
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAdvancedSyntaxErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		msg   string // expected in SyntaxError.Msg
+		pos   int
+	}{
+		{"unterminated phrase", `title:"writ petition`, "unterminated phrase", 6},
+		{"empty phrase", `title:""`, "empty phrase", 6},
+		{"unclosed group", "(bail OR writ", "expected ) to close the group opened at 0", 13},
+		{"unopened group", "bail OR writ)", "unexpected", 12},
+		{"unknown field", "judge:rao", `unknown field "judge"`, 0},
+		{"nested field", "title:author:rao", `field "author" inside field "title"`, 6},
+		{"missing field value", "title: AND bail", `missing value for field "title"`, 7},
+		{"fuzziness too large", "kerela~3", "fuzziness must be ~1 or ~2", 6},
+		{"fuzziness not a number", "kerela~x", "fuzziness must be ~1 or ~2", 6},
+		{"leading wildcard", "*bail", "wildcards are not allowed at the start of a term", 0},
+		{"leading wildcard in field", "case:?RL", "wildcards are not allowed at the start of a term", 5},
+		{"range without TO", "created:[2019-01-01 2019-12-31]", "expected [from TO to]", 20},
+		{"unclosed range", "created:[2019-01-01 TO 2019-12-31", "expected [from TO to]", 8},
+		{"range on a text field", "title:[a TO b]", "ranges need a date or number field", 6},
+		{"open range", "created:[* TO *]", "a range needs at least one end", 8},
+		{"bad date", "created:[2019-13-45 TO *]", "is not a date", 8},
+		{"bad number", "collection:abc", `"abc" is not a number`, 11},
+		{"dangling AND", "bail AND", "AND needs a term on its right", 8},
+		{"leading AND", "AND bail", "AND needs a term on its left", 0},
+		{"too long", strings.Repeat("a ", maxAdvancedQueryLength), "query longer than", maxAdvancedQueryLength},
+		{"too deep", strings.Repeat("(", maxAdvancedDepth+1) + "bail" + strings.Repeat(")", maxAdvancedDepth+1), "too many nested groups", maxAdvancedDepth},
+	}
+	for _, tc := range cases {
+		_, err := ParseAdvanced(tc.input)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%s: got %v, want a SyntaxError", tc.name, err)
+			continue
+		}
+		if !strings.Contains(se.Msg, tc.msg) || se.Pos != tc.pos {
+			t.Errorf("%s: got %q at %d, want %q at %d", tc.name, se.Msg, se.Pos, tc.msg, tc.pos)
+		}
+	}
+}
+
+func TestParseAdvancedAccepts(t *testing.T) {
+	for _, input := range []string{
+		`title:"writ petition" AND petitioner:State -rejected`,
+		"respondent:kerela~1 OR case:CRL*",
+		"event_date:[2019-01-01 TO 2019-12-31] NOT status:withdrawn",
+		"created:>=2020-01-01 collection:3",
+		"(bail OR (anticipatory AND granted)) party:(state OR union)",
+		"created:[2020-01-01 TO *]",
+	} {
+		if _, err := ParseAdvanced(input); err != nil {
+			t.Errorf("%q: %v", input, err)
+		}
+	}
+}
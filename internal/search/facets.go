@@ -0,0 +1,128 @@
+// internal/search/facets.go
+package search
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2"
+	bsearch "github.com/blevesearch/bleve/v2/search"
+	"github.com/mohan2020coder/mSpace/internal/models"
+)
+
+// BleveFacets holds whole-value copies of the fields results can be grouped by;
+// they are indexed with the keyword analyzer so "State of Kerala" counts as one
+// term. Empty values are reported as missing rather than as a term.
+type BleveFacets struct {
+	Collection       string   `json:"Collection"`
+	Author           string   `json:"Author"`
+	Status           string   `json:"Status"`
+	Visibility       string   `json:"Visibility"`
+	Year             string   `json:"Year"` // upload year
+	Petitioners      []string `json:"Petitioners"`
+	Respondents      []string `json:"Respondents"`
+	CaseNumberPrefix string   `json:"CaseNumberPrefix"`
+}
+
+// FacetFields maps the facet names accepted by the API to index fields
+var FacetFields = map[string]string{
+	"collection":         "Facet.Collection",
+	"author":             "Facet.Author",
+	"status":             "Facet.Status",
+	"visibility":         "Facet.Visibility",
+	"year":               "Facet.Year",
+	"petitioners":        "Facet.Petitioners",
+	"respondents":        "Facet.Respondents",
+	"case_number_prefix": "Facet.CaseNumberPrefix",
+}
+
+// DefaultFacetSize is the number of terms returned per facet
+const DefaultFacetSize = 10
+
+// FacetTerm is one value of a facet and the number of hits that have it
+type FacetTerm struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facet is the term counts of one facet over the whole result set
+type Facet struct {
+	Total   int         `json:"total"`
+	Missing int         `json:"missing"` // hits without a value
+	Other   int         `json:"other"`   // hits with values beyond the returned terms
+	Terms   []FacetTerm `json:"terms"`
+}
+
+func itemFacets(item *models.Item, ld *LegalDocument) BleveFacets {
+	f := BleveFacets{
+		Author:     strings.TrimSpace(item.Author),
+		Status:     item.Status,
+		Visibility: item.Visibility,
+	}
+	if item.CollectionID > 0 {
+		f.Collection = strconv.FormatUint(uint64(item.CollectionID), 10)
+	}
+	if !item.CreatedAt.IsZero() {
+		f.Year = strconv.Itoa(item.CreatedAt.Year())
+	}
+	if ld != nil {
+		f.Petitioners = trimAll(ld.Petitioners)
+		f.Respondents = trimAll(ld.Respondents)
+		f.CaseNumberPrefix = CaseNumberPrefix(ld.CaseNumber)
+	}
+	return f
+}
+
+// CaseNumberPrefix returns the part of a case number before its first digit, e.g.
+// "CRL.A" for "CRL.A/123/2019"; case numbers that start with a digit have none
+func CaseNumberPrefix(caseNumber string) string {
+	i := strings.IndexFunc(caseNumber, unicode.IsDigit)
+	if i < 0 {
+		i = len(caseNumber)
+	}
+	return strings.ToUpper(strings.TrimRight(caseNumber[:i], " /-_.:"))
+}
+
+func trimAll(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// addFacets requests the named facets; unknown names are ignored
+func addFacets(req *bleve.SearchRequest, names []string, size int) {
+	if size <= 0 {
+		size = DefaultFacetSize
+	}
+	for _, name := range names {
+		if field, ok := FacetFields[name]; ok {
+			req.AddFacet(name, bleve.NewFacetRequest(field, size))
+		}
+	}
+}
+
+func facetResults(results bsearch.FacetResults) map[string]Facet {
+	if len(results) == 0 {
+		return nil
+	}
+	out := make(map[string]Facet, len(results))
+	for name, r := range results {
+		f := Facet{Total: r.Total, Missing: r.Missing, Other: r.Other, Terms: []FacetTerm{}}
+		if r.Terms != nil {
+			for _, t := range r.Terms.Terms() {
+				if t.Term == "" {
+					f.Missing += t.Count
+					continue
+				}
+				f.Terms = append(f.Terms, FacetTerm{Value: t.Term, Count: t.Count})
+			}
+		}
+		out[name] = f
+	}
+	return out
+}
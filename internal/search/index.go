@@ -4,8 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/blevesearch/bleve/v2"
+	bsearch "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
 	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/mohan2020coder/mSpace/internal/models"
 )
@@ -15,6 +20,14 @@ type BleveEvent struct {
 	Event string `json:"Event"`
 }
 
+// BlevePage is one page of one of the item's files; Sequence is the file's
+// position in the ORIGINAL bundle and Page is 1-based
+type BlevePage struct {
+	Sequence int    `json:"Sequence"`
+	Page     int    `json:"Page"`
+	Text     string `json:"Text"`
+}
+
 type BleveDoc struct {
 	ID           uint         `json:"ID"`
 	Title        string       `json:"Title"`
@@ -23,50 +36,185 @@ type BleveDoc struct {
 	FullText     string       `json:"FullText"`
 	CollectionID uint         `json:"CollectionID"`
 	Visibility   string       `json:"Visibility"`
+	SubmitterID  uint         `json:"SubmitterID"`
+	EmbargoUntil *time.Time   `json:"EmbargoUntil,omitempty"`
 	Petitioners  []string     `json:"Petitioners"`
 	Respondents  []string     `json:"Respondents"`
 	Events       []BleveEvent `json:"Events"`
 	Synopsis     string       `json:"Synopsis"`
+	CaseNumber   string       `json:"CaseNumber"`
+	Status       string       `json:"Status"`
+	CreatedAt    time.Time    `json:"CreatedAt"`
+	UpdatedAt    time.Time    `json:"UpdatedAt"` // row version indexed, compared by the reconciler
+	Pages        []BlevePage  `json:"Pages"`
+	Facet        BleveFacets  `json:"Facet"`
+	Sort         BleveSort    `json:"Sort"`
+}
+
+// BleveSort holds unanalyzed sort keys
+type BleveSort struct {
+	Title string `json:"Title"` // lower-cased so sorting ignores case
+}
+
+// sortFields maps the API sort keys to bleve sort fields, ascending
+var sortFields = map[string]string{
+	"relevance": "_score",
+	"date":      "CreatedAt",
+	"title":     "Sort.Title",
+}
+
+// PageHit is a page whose text matched the query
+type PageHit struct {
+	Sequence int `json:"sequence"`
+	Page     int `json:"page"`
+}
+
+// Hit is one matching item with its score, highlighted fragments (HTML, keyed by
+// field) and the fields the query matched
+type Hit struct {
+	ID            uint                `json:"id"`
+	Score         float64             `json:"score"`
+	Fragments     map[string][]string `json:"fragments,omitempty"`
+	MatchedFields []string            `json:"matched_fields,omitempty"`
+	Pages         []PageHit           `json:"pages,omitempty"`
+}
+
+// highlightFields are the fields fragments are returned for, in snippet priority
+var highlightFields = []string{"FullText", "Pages.Text", "Abstract", "Synopsis", "Title"}
+
+// Snippet returns the most useful highlighted fragment: body text first, then
+// abstract, synopsis and title
+func (h Hit) Snippet() string {
+	for _, f := range highlightFields {
+		if frags := h.Fragments[resultField(f)]; len(frags) > 0 {
+			return frags[0]
+		}
+	}
+	return ""
+}
+
+// resultField reports page text matches as FullText; callers don't see how text is split into pages
+func resultField(field string) string {
+	if field == "Pages.Text" {
+		return "FullText"
+	}
+	return field
+}
+
+// Access limits search results to what the caller may see
+type Access struct {
+	All           bool   // admins see everything
+	UserID        uint   // 0 for anonymous callers
+	CollectionIDs []uint // collections the caller is a member of
 }
 
 type SearchIndex struct {
 	Index bleve.Index
+
+	// Rebuilt is set when NewIndex replaced an index built with PreviousVersion
+	// of the mapping; the new index is empty until items are reindexed
+	Rebuilt         bool
+	PreviousVersion string
 }
 
+// NewIndex opens the index at path, creating it with the current mapping if it
+// does not exist. An index built with a different MappingVersion is discarded and
+// recreated empty; Rebuilt tells the caller to reindex from the database.
 func NewIndex(path string) (*SearchIndex, error) {
-	var idx bleve.Index
-	var err error
-
-	if _, err = os.Stat(path); os.IsNotExist(err) {
-		mapping := bleve.NewIndexMapping()
-		idx, err = bleve.New(path, mapping)
+	if _, err := os.Stat(path); err == nil {
+		idx, err := bleve.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		version, err := idx.GetInternal(mappingVersionKey)
 		if err != nil {
+			idx.Close()
 			return nil, err
 		}
-	} else {
-		idx, err = bleve.Open(path)
+		if string(version) == MappingVersion {
+			return &SearchIndex{Index: idx}, nil
+		}
+		idx.Close()
+		if err := os.RemoveAll(path); err != nil {
+			return nil, fmt.Errorf("remove index with mapping version %q: %w", version, err)
+		}
+		s, err := createIndex(path)
 		if err != nil {
 			return nil, err
 		}
+		s.Rebuilt, s.PreviousVersion = true, string(version)
+		return s, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
 	}
+	return createIndex(path)
+}
 
+func createIndex(path string) (*SearchIndex, error) {
+	m, err := buildMapping()
+	if err != nil {
+		return nil, err
+	}
+	idx, err := bleve.New(path, m)
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.SetInternal(mappingVersionKey, []byte(MappingVersion)); err != nil {
+		idx.Close()
+		return nil, err
+	}
 	return &SearchIndex{Index: idx}, nil
 }
 
-func (s *SearchIndex) IndexItem(item *models.Item) error {
+// IndexItem indexes the item with the per-page text of its current files
+func (s *SearchIndex) IndexItem(item *models.Item, pages []BlevePage) error {
+	return s.Index.Index(fmt.Sprintf("%d", item.ID), newBleveDoc(item, pages))
+}
+
+// ItemDoc is an item and its page texts, as passed to IndexItems
+type ItemDoc struct {
+	Item  *models.Item
+	Pages []BlevePage
+}
+
+// IndexItems indexes several items in one batch
+func (s *SearchIndex) IndexItems(docs []ItemDoc) error {
+	batch := s.Index.NewBatch()
+	for _, d := range docs {
+		if err := batch.Index(fmt.Sprintf("%d", d.Item.ID), newBleveDoc(d.Item, d.Pages)); err != nil {
+			return err
+		}
+	}
+	return s.Index.Batch(batch)
+}
+
+// newBleveDoc builds the indexed form of an item. Items without page text (indexed
+// before pages were kept) fall back to FullText.
+func newBleveDoc(item *models.Item, pages []BlevePage) BleveDoc {
 	bleveDoc := BleveDoc{
 		ID:           item.ID,
 		Title:        item.Title,
 		Author:       item.Author,
 		Abstract:     item.Abstract,
-		FullText:     item.FullText,
 		CollectionID: item.CollectionID,
 		Visibility:   item.Visibility,
+		SubmitterID:  item.SubmitterID,
+		EmbargoUntil: item.EmbargoUntil,
+		Status:       item.Status,
+		CreatedAt:    item.CreatedAt,
+		UpdatedAt:    item.UpdatedAt,
+		Pages:        pages,
+	}
+	if len(pages) == 0 {
+		bleveDoc.FullText = item.FullText
 	}
 
+	var legal *LegalDocument
 	if item.LegalJSON != "" {
 		var ld LegalDocument
 		if err := json.Unmarshal([]byte(item.LegalJSON), &ld); err == nil {
+			legal = &ld
+			bleveDoc.CaseNumber = strings.ToUpper(ld.CaseNumber) // advanced queries upper-case case: values
 			bleveDoc.Petitioners = ld.Petitioners
 			bleveDoc.Respondents = ld.Respondents
 			for _, e := range ld.Events {
@@ -75,56 +223,227 @@ func (s *SearchIndex) IndexItem(item *models.Item) error {
 			bleveDoc.Synopsis = ld.Synopsis
 		}
 	}
+	bleveDoc.Facet = itemFacets(item, legal)
+	bleveDoc.Sort.Title = strings.ToLower(strings.TrimSpace(item.Title))
 
-	return s.Index.Index(fmt.Sprintf("%d", item.ID), bleveDoc)
+	return bleveDoc
 }
 
-func (s *SearchIndex) Search(queryStr string, collectionID uint, author string) ([]uint, error) {
-	var queries []query.Query
+// DeleteItem removes the item from the index; unknown ids are not an error
+func (s *SearchIndex) DeleteItem(id uint) error {
+	return s.Index.Delete(fmt.Sprintf("%d", id))
+}
 
-	if queryStr == "*" || queryStr == "" {
-		queries = append(queries, bleve.NewMatchAllQuery())
-	} else {
-		fields := []string{"Title", "Abstract", "FullText", "Petitioners", "Respondents", "Events.Event", "Synopsis"}
-		for _, f := range fields {
-			q := bleve.NewMatchQuery(queryStr)
-			q.SetField(f)
-			queries = append(queries, q)
+// IndexedItems returns every indexed item id with the UpdatedAt it was indexed at
+func (s *SearchIndex) IndexedItems() (map[uint]time.Time, error) {
+	items := map[uint]time.Time{}
+	err := s.scan(bleve.NewMatchAllQuery(), []string{"UpdatedAt"}, func(id uint, hit *bsearch.DocumentMatch) {
+		var updated time.Time
+		if v, ok := hit.Fields["UpdatedAt"].(string); ok {
+			updated, _ = time.Parse(time.RFC3339Nano, v)
 		}
-	}
+		items[id] = updated
+	})
+	return items, err
+}
 
-	if collectionID > 0 {
-		val := float64(collectionID)
-		numQuery := bleve.NewNumericRangeQuery(&val, &val)
-		numQuery.SetField("CollectionID")
-		queries = append(queries, numQuery)
+// Matching returns the ids of the items that pass q's filters and the caller's
+// access, whatever its text; semantic search uses it to honour the same filters
+func (s *SearchIndex) Matching(q Query, access Access) (map[uint]bool, error) {
+	q.Text = ""
+	filter, err := q.Build(access)
+	if err != nil {
+		return nil, err
 	}
+	ids := map[uint]bool{}
+	err = s.scan(filter, nil, func(id uint, _ *bsearch.DocumentMatch) { ids[id] = true })
+	return ids, err
+}
 
-	if author != "" {
-		authorQuery := bleve.NewMatchQuery(author)
-		authorQuery.SetField("Author")
-		queries = append(queries, authorQuery)
+// scan calls fn for every document matching qry, paging through the index in id order
+func (s *SearchIndex) scan(qry query.Query, fields []string, fn func(id uint, hit *bsearch.DocumentMatch)) error {
+	const batch = 1000
+	var after []string
+	for {
+		req := bleve.NewSearchRequestOptions(qry, batch, 0, false)
+		req.Fields = fields
+		req.SortBy([]string{"_id"})
+		req.SearchAfter = after
+		res, err := s.Index.Search(req)
+		if err != nil {
+			return err
+		}
+		for _, hit := range res.Hits {
+			var id uint
+			fmt.Sscanf(hit.ID, "%d", &id)
+			fn(id, hit)
+		}
+		if len(res.Hits) < batch {
+			return nil
+		}
+		after = []string{res.Hits[len(res.Hits)-1].ID}
 	}
+}
 
-	var finalQuery query.Query
-	if len(queries) == 1 {
-		finalQuery = queries[0]
-	} else {
-		finalQuery = bleve.NewDisjunctionQuery(queries...)
+// Result is one page of hits plus the facet counts over all matches
+type Result struct {
+	Total  uint64           `json:"total"`
+	Hits   []Hit            `json:"hits"`
+	Facets map[string]Facet `json:"facets,omitempty"`
+}
+
+// Search runs q with the caller's access applied and returns the requested page of hits
+func (s *SearchIndex) Search(q Query, access Access) (*Result, error) {
+	finalQuery, err := q.Build(access)
+	if err != nil {
+		return nil, err
 	}
 
-	searchRequest := bleve.NewSearchRequestOptions(finalQuery, 100, 0, false)
+	size := q.Size
+	if size <= 0 {
+		size = 100
+	}
+	searchRequest := bleve.NewSearchRequestOptions(finalQuery, size, q.Offset, false)
+	if field, ok := sortFields[q.Sort]; ok {
+		if q.Desc {
+			field = "-" + field
+		}
+		searchRequest.SortBy([]string{field, "_id"})
+	}
+	addFacets(searchRequest, q.Facets, q.FacetSize)
+	searchRequest.IncludeLocations = true
+	searchRequest.Fields = []string{"Pages.Sequence", "Pages.Page"}
+	searchRequest.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	searchRequest.Highlight.Fields = highlightFields
 	searchResult, err := s.Index.Search(searchRequest)
 	if err != nil {
 		return nil, err
 	}
 
-	var ids []uint
+	result := &Result{Total: searchResult.Total, Facets: facetResults(searchResult.Facets)}
 	for _, hit := range searchResult.Hits {
 		var id uint
 		fmt.Sscanf(hit.ID, "%d", &id)
-		ids = append(ids, id)
+		result.Hits = append(result.Hits, Hit{
+			ID:            id,
+			Score:         hit.Score,
+			Fragments:     fragments(hit),
+			MatchedFields: matchedFields(hit),
+			Pages:         matchedPages(hit),
+		})
+	}
+
+	return result, nil
+}
+
+// fragments collects the highlighted fragments of matched fields under their result field names
+func fragments(hit *bsearch.DocumentMatch) map[string][]string {
+	var out map[string][]string
+	for _, f := range highlightFields {
+		if len(hit.Locations[f]) == 0 {
+			continue
+		}
+		for _, frag := range hit.Fragments[f] {
+			if frag = strings.TrimSpace(frag); frag != "" {
+				if out == nil {
+					out = map[string][]string{}
+				}
+				name := resultField(f)
+				out[name] = append(out[name], frag)
+			}
+		}
 	}
+	return out
+}
+
+// matchedFields lists the fields with term matches, in a stable order
+func matchedFields(hit *bsearch.DocumentMatch) []string {
+	seen := map[string]bool{}
+	var fields []string
+	for f := range hit.Locations {
+		name := resultField(f)
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// matchedPages maps the array positions of Pages.Text matches back to page numbers
+// using the stored Pages.Sequence/Pages.Page values
+func matchedPages(hit *bsearch.DocumentMatch) []PageHit {
+	locations := hit.Locations["Pages.Text"]
+	if len(locations) == 0 {
+		return nil
+	}
+	sequences, numbers := storedInts(hit.Fields["Pages.Sequence"]), storedInts(hit.Fields["Pages.Page"])
+
+	seen := map[uint64]bool{}
+	for _, locs := range locations {
+		for _, loc := range locs {
+			if len(loc.ArrayPositions) > 0 {
+				seen[loc.ArrayPositions[0]] = true
+			}
+		}
+	}
+	var pages []PageHit
+	for pos := range seen {
+		if int(pos) < len(numbers) && int(pos) < len(sequences) {
+			pages = append(pages, PageHit{Sequence: sequences[pos], Page: numbers[pos]})
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		if pages[i].Sequence != pages[j].Sequence {
+			return pages[i].Sequence < pages[j].Sequence
+		}
+		return pages[i].Page < pages[j].Page
+	})
+	return pages
+}
+
+// storedInts reads a stored numeric field, which bleve returns as a single value
+// for one-element arrays and as a slice otherwise
+func storedInts(v any) []int {
+	switch t := v.(type) {
+	case float64:
+		return []int{int(t)}
+	case []any:
+		out := make([]int, 0, len(t))
+		for _, e := range t {
+			f, _ := e.(float64)
+			out = append(out, int(f))
+		}
+		return out
+	}
+	return nil
+}
+
+// visibilityQuery matches public items, items whose embargo has passed but was not
+// lifted yet, and private ones the caller submitted or is a member of, like the
+// visibleItems scope of the API
+func visibilityQuery(access Access) query.Query {
+	public := bleve.NewMatchQuery("PUBLIC")
+	public.SetField("Visibility")
+	inclusive := true
+	embargoOver := bleve.NewDateRangeInclusiveQuery(time.Time{}, time.Now(), nil, &inclusive)
+	embargoOver.SetField("EmbargoUntil")
+	allowed := []query.Query{public, embargoOver}
+
+	if access.UserID > 0 {
+		allowed = append(allowed, numericTermQuery("SubmitterID", access.UserID))
+	}
+	for _, cid := range access.CollectionIDs {
+		allowed = append(allowed, numericTermQuery("CollectionID", cid))
+	}
+	return bleve.NewDisjunctionQuery(allowed...)
+}
 
-	return ids, nil
+func numericTermQuery(field string, v uint) query.Query {
+	val := float64(v)
+	inclusive := true
+	q := bleve.NewNumericRangeInclusiveQuery(&val, &val, &inclusive, &inclusive)
+	q.SetField(field)
+	return q
 }
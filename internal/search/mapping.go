@@ -0,0 +1,104 @@
+// internal/search/mapping.go
+package search
+
+import (
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/datetime/flexible"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// MappingVersion identifies the layout built by buildMapping. Bump it whenever the
+// mapping or BleveDoc changes; an index built with another version is rebuilt.
+const MappingVersion = "4"
+
+// mappingVersionKey is where the version is kept in the index's internal storage
+var mappingVersionKey = []byte("mapping_version")
+
+// legalDateParser reads the dd/mm/yyyy dates of parsed judgments
+const legalDateParser = "legal_date"
+
+// buildMapping describes BleveDoc field by field. Prose is stemmed with the English
+// analyzer, names use the standard analyzer, and identifiers and states are keywords.
+// Text fields are stored with term vectors so hits can be highlighted and mapped to pages.
+func buildMapping() (*mapping.IndexMappingImpl, error) {
+	im := bleve.NewIndexMapping()
+	err := im.AddCustomDateTimeParser(legalDateParser, map[string]interface{}{
+		"type":    flexible.Name,
+		"layouts": []interface{}{"02/01/2006", "2006-01-02", "2006-01-02T15:04:05Z07:00"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("ID", numericField(true))
+	doc.AddFieldMappingsAt("Title", textField(en.AnalyzerName))
+	doc.AddFieldMappingsAt("Author", textField(standard.Name))
+	doc.AddFieldMappingsAt("Abstract", textField(en.AnalyzerName))
+	doc.AddFieldMappingsAt("FullText", textField(en.AnalyzerName))
+	doc.AddFieldMappingsAt("Synopsis", textField(en.AnalyzerName))
+	doc.AddFieldMappingsAt("Petitioners", textField(standard.Name))
+	doc.AddFieldMappingsAt("Respondents", textField(standard.Name))
+	doc.AddFieldMappingsAt("CollectionID", numericField(false))
+	doc.AddFieldMappingsAt("SubmitterID", numericField(false))
+	doc.AddFieldMappingsAt("Visibility", keywordField())
+	doc.AddFieldMappingsAt("Status", keywordField())
+	doc.AddFieldMappingsAt("CaseNumber", keywordField())
+	doc.AddFieldMappingsAt("CreatedAt", bleve.NewDateTimeFieldMapping())
+	doc.AddFieldMappingsAt("EmbargoUntil", bleve.NewDateTimeFieldMapping())
+	updated := bleve.NewDateTimeFieldMapping()
+	updated.Store = true
+	doc.AddFieldMappingsAt("UpdatedAt", updated)
+
+	events := bleve.NewDocumentStaticMapping()
+	eventDate := bleve.NewDateTimeFieldMapping()
+	eventDate.DateFormat = legalDateParser
+	events.AddFieldMappingsAt("Date", eventDate)
+	events.AddFieldMappingsAt("Event", textField(en.AnalyzerName))
+	doc.AddSubDocumentMapping("Events", events)
+
+	pages := bleve.NewDocumentStaticMapping()
+	pages.AddFieldMappingsAt("Sequence", numericField(true))
+	pages.AddFieldMappingsAt("Page", numericField(true))
+	pages.AddFieldMappingsAt("Text", textField(en.AnalyzerName))
+	doc.AddSubDocumentMapping("Pages", pages)
+
+	doc.AddSubDocumentMapping("Facet", keywordMapping("Collection", "Author", "Status", "Visibility", "Year",
+		"Petitioners", "Respondents", "CaseNumberPrefix"))
+	doc.AddSubDocumentMapping("Sort", keywordMapping("Title"))
+
+	im.DefaultMapping = doc
+	im.DefaultAnalyzer = standard.Name
+	return im, nil
+}
+
+// keywordMapping is a sub-document of unanalyzed fields, used for facets and sort keys
+func keywordMapping(fields ...string) *mapping.DocumentMapping {
+	m := bleve.NewDocumentStaticMapping()
+	for _, f := range fields {
+		m.AddFieldMappingsAt(f, keywordField())
+	}
+	return m
+}
+
+func textField(analyzer string) *mapping.FieldMapping {
+	f := bleve.NewTextFieldMapping()
+	f.Analyzer = analyzer
+	f.Store = true
+	f.IncludeTermVectors = true
+	return f
+}
+
+func keywordField() *mapping.FieldMapping {
+	f := bleve.NewKeywordFieldMapping()
+	f.Store = false
+	return f
+}
+
+func numericField(store bool) *mapping.FieldMapping {
+	f := bleve.NewNumericFieldMapping()
+	f.Store = store
+	return f
+}
@@ -0,0 +1,137 @@
+// internal/search/query.go
+package search
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// textFields are searched for the free text part of a query
+var textFields = []string{"Title", "Abstract", "FullText", "Pages.Text", "Petitioners", "Respondents", "Events.Event", "Synopsis"}
+
+// Query is a search request: free text matched against any text field, narrowed
+// by filters that every hit must satisfy
+type Query struct {
+	Text         string // "" or "*" matches everything
+	Advanced     bool   // Text uses the ParseAdvanced syntax
+	CollectionID uint
+	Author       string
+	Visibility   string     // PUBLIC/PRIVATE
+	Status       string     // workflow state
+	From, To     *time.Time // creation date range, both ends inclusive
+
+	// Drill-down filters on facet values, matched exactly
+	Year             int
+	Petitioner       string
+	Respondent       string
+	CaseNumberPrefix string
+
+	Facets    []string // facet names from FacetFields to count
+	FacetSize int      // terms per facet, DefaultFacetSize if 0
+
+	// Paging and ordering; Sort is relevance (the default), date or title
+	Size, Offset int
+	Sort         string
+	Desc         bool
+}
+
+// Build turns q into a bleve query: the text clauses are a disjunction (any field
+// may match) and each filter, including the caller's access, is a must clause
+func (q Query) Build(access Access) (query.Query, error) {
+	text, err := q.textQuery()
+	if err != nil {
+		return nil, err
+	}
+	must := []query.Query{text}
+
+	if q.CollectionID > 0 {
+		must = append(must, numericTermQuery("CollectionID", q.CollectionID))
+	}
+	if q.Author != "" {
+		author := bleve.NewMatchQuery(q.Author)
+		author.SetField("Author")
+		author.SetOperator(query.MatchQueryOperatorAnd)
+		must = append(must, author)
+	}
+	if q.Visibility != "" {
+		must = append(must, keywordQuery("Visibility", q.Visibility))
+	}
+	if q.Status != "" {
+		must = append(must, keywordQuery("Status", q.Status))
+	}
+	if q.From != nil || q.To != nil {
+		var from, to time.Time
+		if q.From != nil {
+			from = *q.From
+		}
+		if q.To != nil {
+			to = *q.To
+		}
+		inclusive := true
+		created := bleve.NewDateRangeInclusiveQuery(from, to, &inclusive, &inclusive)
+		created.SetField("CreatedAt")
+		must = append(must, created)
+	}
+	if q.Year > 0 {
+		must = append(must, facetQuery("year", strconv.Itoa(q.Year)))
+	}
+	if q.Petitioner != "" {
+		must = append(must, facetQuery("petitioners", q.Petitioner))
+	}
+	if q.Respondent != "" {
+		must = append(must, facetQuery("respondents", q.Respondent))
+	}
+	if q.CaseNumberPrefix != "" {
+		must = append(must, facetQuery("case_number_prefix", strings.ToUpper(q.CaseNumberPrefix)))
+	}
+	if !access.All {
+		must = append(must, visibilityQuery(access))
+	}
+
+	if len(must) == 1 {
+		return must[0], nil
+	}
+	return bleve.NewConjunctionQuery(must...), nil
+}
+
+// Filtered reports whether q narrows its results beyond the text and the caller's access
+func (q Query) Filtered() bool {
+	return q.CollectionID > 0 || q.Author != "" || q.Visibility != "" || q.Status != "" ||
+		q.From != nil || q.To != nil || q.Year > 0 || q.Petitioner != "" || q.Respondent != "" ||
+		q.CaseNumberPrefix != ""
+}
+
+func (q Query) textQuery() (query.Query, error) {
+	if q.Text == "" || q.Text == "*" {
+		return bleve.NewMatchAllQuery(), nil
+	}
+	if q.Advanced {
+		return ParseAdvanced(q.Text)
+	}
+	var fields []query.Query
+	for _, f := range textFields {
+		m := bleve.NewMatchQuery(q.Text)
+		m.SetField(f)
+		fields = append(fields, m)
+	}
+	return bleve.NewDisjunctionQuery(fields...), nil
+}
+
+// facetQuery matches a facet value exactly, as returned in the facet terms
+func facetQuery(name, value string) query.Query {
+	t := bleve.NewTermQuery(value)
+	t.SetField(FacetFields[name])
+	return t
+}
+
+// keywordQuery matches a single-token field such as Visibility or Status
+func keywordQuery(field, value string) query.Query {
+	m := bleve.NewMatchQuery(value)
+	m.SetField(field)
+	m.SetOperator(query.MatchQueryOperatorAnd)
+	return m
+}
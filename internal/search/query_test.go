@@ -0,0 +1,189 @@
+// internal/search/query_test.go
+package search
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+
+	"github.com/mohan2020coder/mSpace/internal/models"
+)
+
+// newTestIndex indexes the items in a memory-only index with the production mapping
+func newTestIndex(t *testing.T, items ...models.Item) *SearchIndex {
+	t.Helper()
+	m, err := buildMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := bleve.NewMemOnly(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { idx.Close() })
+	s := &SearchIndex{Index: idx}
+	for i := range items {
+		if err := s.IndexItem(&items[i], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func testItem(id uint, title, author string, collection uint, status, visibility string, submitter uint, created string) models.Item {
+	it := models.Item{Title: title, Author: author, CollectionID: collection, Status: status, Visibility: visibility, SubmitterID: submitter}
+	it.ID = id
+	it.CreatedAt, _ = time.Parse("2006-01-02", created)
+	it.UpdatedAt = it.CreatedAt
+	return it
+}
+
+func hitIDs(t *testing.T, s *SearchIndex, q Query, access Access) []uint {
+	t.Helper()
+	res, err := s.Search(q, access)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []uint
+	for _, h := range res.Hits {
+		ids = append(ids, h.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func date(s string) *time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return &t
+}
+
+// corpus has one item matching every clause of filteredQuery, and one item per
+// clause that matches all the others but that one
+func corpus(t *testing.T) *SearchIndex {
+	return newTestIndex(t,
+		testItem(1, "Anticipatory bail granted", "Asha Rao", 1, "PUBLISHED", "PUBLIC", 10, "2020-03-01"),
+		testItem(2, "Anticipatory bail granted", "Asha Rao", 2, "PUBLISHED", "PUBLIC", 10, "2020-03-01"),    // other collection
+		testItem(3, "Anticipatory bail granted", "Vikram Shah", 1, "PUBLISHED", "PUBLIC", 10, "2020-03-01"), // other author
+		testItem(4, "Anticipatory bail granted", "Asha Rao", 1, "DRAFT", "PUBLIC", 10, "2020-03-01"),        // other status
+		testItem(5, "Anticipatory bail granted", "Asha Rao", 1, "PUBLISHED", "PUBLIC", 10, "2022-05-01"),    // outside the dates
+		testItem(6, "Property tax appeal", "Asha Rao", 1, "PUBLISHED", "PUBLIC", 10, "2020-03-01"),          // text does not match
+		testItem(7, "Anticipatory bail granted", "Asha Rao", 1, "PUBLISHED", "PRIVATE", 99, "2020-03-01"),   // private to user 99
+		testItem(8, "Anticipatory bail granted", "Asha Rao", 3, "PUBLISHED", "PRIVATE", 98, "2020-03-01"),   // private, collection 3
+	)
+}
+
+func filteredQuery() Query {
+	return Query{
+		Text:         "bail",
+		CollectionID: 1,
+		Author:       "asha",
+		Status:       "PUBLISHED",
+		From:         date("2020-01-01"),
+		To:           date("2020-12-31"),
+	}
+}
+
+func TestBuildFiltersAreConjunctive(t *testing.T) {
+	s := corpus(t)
+	anonymous := Access{}
+
+	if got := hitIDs(t, s, filteredQuery(), anonymous); !slices.Equal(got, []uint{1}) {
+		t.Fatalf("all filters: got %v, want [1]", got)
+	}
+
+	// Dropping one clause lets in exactly the item that only that clause excluded,
+	// so every clause is ANDed with all the others
+	cases := []struct {
+		name string
+		drop func(*Query)
+		want []uint
+	}{
+		{"text", func(q *Query) { q.Text = "" }, []uint{1, 6}},
+		{"collection", func(q *Query) { q.CollectionID = 0 }, []uint{1, 2}},
+		{"author", func(q *Query) { q.Author = "" }, []uint{1, 3}},
+		{"status", func(q *Query) { q.Status = "" }, []uint{1, 4}},
+		{"date range", func(q *Query) { q.From, q.To = nil, nil }, []uint{1, 5}},
+	}
+	for _, tc := range cases {
+		q := filteredQuery()
+		tc.drop(&q)
+		if got := hitIDs(t, s, q, anonymous); !slices.Equal(got, tc.want) {
+			t.Errorf("without %s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestBuildAuthorMatchesAllTerms(t *testing.T) {
+	s := corpus(t)
+	q := filteredQuery()
+	q.Author = "asha shah" // no author has both names
+	if got := hitIDs(t, s, q, Access{All: true}); len(got) != 0 {
+		t.Fatalf("author terms should all be required, got %v", got)
+	}
+}
+
+func TestBuildDateRangeIsInclusive(t *testing.T) {
+	s := corpus(t)
+	q := filteredQuery()
+	q.From, q.To = date("2020-03-01"), date("2020-03-01")
+	q.To = ptr(q.To.Add(24*time.Hour - time.Nanosecond))
+	if got := hitIDs(t, s, q, Access{}); !slices.Equal(got, []uint{1}) {
+		t.Fatalf("single day range: got %v, want [1]", got)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestBuildAccessCannotBeBypassed(t *testing.T) {
+	s := corpus(t)
+
+	cases := []struct {
+		name   string
+		q      Query
+		access Access
+		want   []uint
+	}{
+		{"anonymous sees public only", Query{Text: "bail"}, Access{}, []uint{1, 2, 3, 4, 5}},
+		{"visibility filter cannot widen access", Query{Text: "bail", Visibility: "PRIVATE"}, Access{}, nil},
+		{"collection filter cannot widen access", Query{Text: "bail", CollectionID: 3}, Access{UserID: 10}, nil},
+		{"advanced OR cannot escape the restriction", Query{Text: "visibility:PRIVATE OR bail OR collection:3", Advanced: true}, Access{}, []uint{1, 2, 3, 4, 5}},
+		{"advanced NOT cannot escape the restriction", Query{Text: "-title:property", Advanced: true}, Access{}, []uint{1, 2, 3, 4, 5}},
+		{"match all respects the restriction", Query{Text: "*"}, Access{}, []uint{1, 2, 3, 4, 5, 6}},
+		{"submitter sees own private item", Query{Text: "bail"}, Access{UserID: 99}, []uint{1, 2, 3, 4, 5, 7}},
+		{"member sees private items of the collection", Query{Text: "bail"}, Access{UserID: 5, CollectionIDs: []uint{3}}, []uint{1, 2, 3, 4, 5, 8}},
+		{"admin sees everything", Query{Text: "bail"}, Access{All: true}, []uint{1, 2, 3, 4, 5, 7, 8}},
+	}
+	for _, tc := range cases {
+		if got := hitIDs(t, s, tc.q, tc.access); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAdvancedCaseNumberIgnoresCase(t *testing.T) {
+	item := testItem(1, "Appeal", "Asha Rao", 1, "PUBLISHED", "PUBLIC", 10, "2020-03-01")
+	item.LegalJSON = `{"CaseNumber":"Crl.A 12"}`
+	s := newTestIndex(t, item)
+	for _, text := range []string{"case:crl*", "case:CRL*", `case_number:"crl.a 12"`} {
+		if got := hitIDs(t, s, Query{Text: text, Advanced: true}, Access{}); !slices.Equal(got, []uint{1}) {
+			t.Errorf("%s: got %v, want [1]", text, got)
+		}
+	}
+}
+
+func TestBuildExpiredEmbargoIsPublic(t *testing.T) {
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour)
+	expired := testItem(1, "Bail order", "Asha Rao", 1, "PUBLISHED", "PRIVATE", 10, "2020-03-01")
+	expired.EmbargoUntil = &past
+	embargoed := testItem(2, "Bail order", "Asha Rao", 1, "PUBLISHED", "PRIVATE", 10, "2020-03-01")
+	embargoed.EmbargoUntil = &future
+	private := testItem(3, "Bail order", "Asha Rao", 1, "PUBLISHED", "PRIVATE", 10, "2020-03-01")
+	s := newTestIndex(t, expired, embargoed, private)
+
+	// Visible before the embargo lifter has made the item PUBLIC, as on item GET
+	if got := hitIDs(t, s, Query{Text: "bail"}, Access{}); !slices.Equal(got, []uint{1}) {
+		t.Fatalf("got %v, want [1]", got)
+	}
+}
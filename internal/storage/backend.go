@@ -0,0 +1,68 @@
+// internal/storage/backend.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mohan2020coder/mSpace/internal/config"
+)
+
+var (
+	ErrNotFound           = errors.New("object not found")
+	ErrPresignUnsupported = errors.New("presigned urls not supported by storage provider")
+)
+
+// Object is an open stored object; it supports random access so it can serve
+// Range requests and be handed to readers that need io.ReaderAt
+type Object interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+}
+
+// ObjectInfo is the metadata of a stored object
+type ObjectInfo struct {
+	Name         string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+	ETag         string
+}
+
+// Backend is a blob store for bitstreams. Object names use "/" separators.
+// Get, Stat and Delete return an error wrapping ErrNotFound for unknown names.
+type Backend interface {
+	Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, name string) (Object, ObjectInfo, error)
+	Stat(ctx context.Context, name string) (ObjectInfo, error)
+	Delete(ctx context.Context, name string) error
+	Presign(ctx context.Context, name string, expiry time.Duration) (string, error)
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// New builds the backend named by cfg.Provider: "minio" (default), "fs" or "memory"
+func New(cfg config.StorageCfg) (Backend, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", "minio":
+		return NewMinio(cfg.Endpoint, cfg.AccessKey, cfg.SecretKey, cfg.Bucket, cfg.SSL)
+	case "fs", "filesystem":
+		return NewFilesystem(cfg.Path)
+	case "memory":
+		return NewMemory(), nil
+	}
+	return nil, fmt.Errorf("unknown storage provider %q", cfg.Provider)
+}
+
+var (
+	_ Backend = (*MinioClient)(nil)
+	_ Backend = (*Filesystem)(nil)
+	_ Backend = (*Memory)(nil)
+)
+
+func notFound(name string) error {
+	return fmt.Errorf("%w: %s", ErrNotFound, name)
+}
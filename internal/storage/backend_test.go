@@ -0,0 +1,217 @@
+// internal/storage/backend_test.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// backends returns a fresh instance of every backend that runs without a server
+func backends(t *testing.T) map[string]Backend {
+	fs, err := NewFilesystem(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Backend{"fs": fs, "memory": NewMemory()}
+}
+
+func put(t *testing.T, b Backend, name, content string) {
+	t.Helper()
+	if err := b.Put(context.Background(), name, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("put %s: %v", name, err)
+	}
+}
+
+func read(t *testing.T, b Backend, name string) string {
+	t.Helper()
+	obj, _, err := b.Get(context.Background(), name)
+	if err != nil {
+		t.Fatalf("get %s: %v", name, err)
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBackendRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for name, b := range backends(t) {
+		put(t, b, "items/1/original/1/v1/a.pdf", "hello")
+		if got := read(t, b, "items/1/original/1/v1/a.pdf"); got != "hello" {
+			t.Errorf("%s: read %q", name, got)
+		}
+		info, err := b.Stat(ctx, "items/1/original/1/v1/a.pdf")
+		if err != nil || info.Size != 5 {
+			t.Errorf("%s: stat %+v, %v", name, info, err)
+		}
+		if err := b.Put(ctx, "short", strings.NewReader("abc"), 10, ""); err == nil {
+			t.Errorf("%s: short write accepted", name)
+		}
+		if _, err := b.Presign(ctx, "items/1/original/1/v1/a.pdf", 0); !errors.Is(err, ErrPresignUnsupported) {
+			t.Errorf("%s: presign %v, want ErrPresignUnsupported", name, err)
+		}
+	}
+}
+
+func TestBackendNotFound(t *testing.T) {
+	ctx := context.Background()
+	for name, b := range backends(t) {
+		if _, _, err := b.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: get %v, want ErrNotFound", name, err)
+		}
+		if _, err := b.Stat(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: stat %v, want ErrNotFound", name, err)
+		}
+		if err := b.Delete(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: delete %v, want ErrNotFound", name, err)
+		}
+		put(t, b, "gone", "x")
+		if err := b.Delete(ctx, "gone"); err != nil {
+			t.Fatalf("%s: delete %v", name, err)
+		}
+		if _, err := b.Stat(ctx, "gone"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: stat after delete %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func TestBackendListByPrefix(t *testing.T) {
+	for name, b := range backends(t) {
+		for _, n := range []string{"items/1/a", "items/1/b/c", "items/10/a", "items/2/a", "other"} {
+			put(t, b, n, n)
+		}
+		infos, err := b.List(context.Background(), "items/1/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, info := range infos {
+			got = append(got, info.Name)
+		}
+		slices.Sort(got)
+		if want := []string{"items/1/a", "items/1/b/c"}; !slices.Equal(got, want) {
+			t.Errorf("%s: list %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestFilesystemStaysBelowRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	fs, err := NewFilesystem(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, name := range []string{"../escape", "a/../../escape", "/../../escape"} {
+		put(t, fs, name, "x")
+		if _, err := os.Stat(filepath.Join(parent, "escape")); err == nil {
+			t.Fatalf("%q was written outside the root", name)
+		}
+		if _, err := os.Stat(filepath.Join(root, "escape")); err != nil {
+			t.Errorf("%q was not kept below the root: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "/", "dir/", ".."} {
+		if err := fs.Put(ctx, name, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("invalid name %q accepted", name)
+		}
+	}
+	// Temp files of interrupted writes are not objects
+	if err := os.WriteFile(filepath.Join(root, ".upload-123"), []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	infos, err := fs.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if strings.HasPrefix(info.Name, ".upload-") {
+			t.Errorf("listed temp file %s", info.Name)
+		}
+	}
+}
+
+func TestStagedMultipart(t *testing.T) {
+	ctx := context.Background()
+	for name, b := range backends(t) {
+		m := MultipartFor(b)
+		if _, native := b.(Multipart); native {
+			t.Fatalf("%s: expected the staged fallback", name)
+		}
+		id, err := m.NewMultipartUpload(ctx, "items/1/big.pdf", "application/pdf")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Parts arrive out of order and are assembled by number
+		var parts []Part
+		for _, p := range []struct {
+			n    int
+			data string
+		}{{3, "three"}, {1, "one-"}, {2, "two-"}} {
+			part, err := m.PutPart(ctx, "items/1/big.pdf", id, p.n, strings.NewReader(p.data), int64(len(p.data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if part.Number != p.n || part.Size != int64(len(p.data)) || part.ETag == "" {
+				t.Errorf("%s: part %+v", name, part)
+			}
+			parts = append(parts, part)
+		}
+		if err := m.CompleteMultipartUpload(ctx, "items/1/big.pdf", id, parts); err != nil {
+			t.Fatal(err)
+		}
+		if got := read(t, b, "items/1/big.pdf"); got != "one-two-three" {
+			t.Errorf("%s: assembled %q", name, got)
+		}
+		if info, _ := b.Stat(ctx, "items/1/big.pdf"); name == "memory" && info.ContentType != "application/pdf" {
+			t.Errorf("%s: content type %q", name, info.ContentType)
+		}
+		if staged, _ := b.List(ctx, stagingPrefix); len(staged) != 0 {
+			t.Errorf("%s: %d staged objects left after complete", name, len(staged))
+		}
+		// The upload id is used up
+		if _, err := m.PutPart(ctx, "items/1/big.pdf", id, 4, strings.NewReader("x"), 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: part after complete %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func TestStagedMultipartAbort(t *testing.T) {
+	ctx := context.Background()
+	for name, b := range backends(t) {
+		m := MultipartFor(b)
+		id, err := m.NewMultipartUpload(ctx, "items/1/big.pdf", "application/pdf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range []int{2, 1} {
+			if _, err := m.PutPart(ctx, "items/1/big.pdf", id, n, strings.NewReader("data"), 4); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := m.AbortMultipartUpload(ctx, "items/1/big.pdf", id); err != nil {
+			t.Fatal(err)
+		}
+		if staged, _ := b.List(ctx, stagingPrefix); len(staged) != 0 {
+			t.Errorf("%s: %d staged objects left after abort", name, len(staged))
+		}
+		if _, err := b.Stat(ctx, "items/1/big.pdf"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: aborted upload produced an object: %v", name, err)
+		}
+		if err := m.CompleteMultipartUpload(ctx, "items/1/big.pdf", id, nil); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: complete after abort %v, want ErrNotFound", name, err)
+		}
+	}
+}
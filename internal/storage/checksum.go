@@ -0,0 +1,59 @@
+// internal/storage/checksum.go
+package storage
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// Digests are the checksums recorded for a stored object, hex encoded
+type Digests struct {
+	SHA256 string
+	MD5    string
+}
+
+// DigestReader computes SHA-256 and MD5 over everything read through it
+type DigestReader struct {
+	r      io.Reader
+	sha256 hash.Hash
+	md5    hash.Hash
+	n      int64
+}
+
+func NewDigestReader(r io.Reader) *DigestReader {
+	return &DigestReader{r: r, sha256: sha256.New(), md5: md5.New()}
+}
+
+func (d *DigestReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if n > 0 {
+		d.sha256.Write(p[:n])
+		d.md5.Write(p[:n])
+		d.n += int64(n)
+	}
+	return n, err
+}
+
+// BytesRead returns how many bytes have passed through the reader
+func (d *DigestReader) BytesRead() int64 {
+	return d.n
+}
+
+func (d *DigestReader) Digests() Digests {
+	return Digests{
+		SHA256: hex.EncodeToString(d.sha256.Sum(nil)),
+		MD5:    hex.EncodeToString(d.md5.Sum(nil)),
+	}
+}
+
+// ComputeDigests reads r to the end and returns its checksums and length
+func ComputeDigests(r io.Reader) (Digests, int64, error) {
+	d := NewDigestReader(r)
+	if _, err := io.Copy(io.Discard, d); err != nil {
+		return Digests{}, d.BytesRead(), err
+	}
+	return d.Digests(), d.BytesRead(), nil
+}
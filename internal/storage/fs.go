@@ -0,0 +1,152 @@
+// internal/storage/fs.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Filesystem stores objects as plain files below Root, for running without MinIO
+type Filesystem struct {
+	Root string
+}
+
+func NewFilesystem(root string) (*Filesystem, error) {
+	if root == "" {
+		root = "./data"
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("create storage dir %s: %w", root, err)
+	}
+	return &Filesystem{Root: root}, nil
+}
+
+// path maps an object name to a file below Root, refusing names that escape it
+func (f *Filesystem) path(name string) (string, error) {
+	clean := path.Clean("/" + name)
+	if clean == "/" || strings.HasSuffix(name, "/") {
+		return "", fmt.Errorf("invalid object name %q", name)
+	}
+	return filepath.Join(f.Root, filepath.FromSlash(clean)), nil
+}
+
+// Put writes to a temp file first so readers never see a partial object
+func (f *Filesystem) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	p, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("short write for %s: got %d of %d bytes", name, n, size)
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (f *Filesystem) Get(ctx context.Context, name string) (Object, ObjectInfo, error) {
+	p, err := f.path(name)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	file, err := os.Open(p)
+	if err != nil {
+		return nil, ObjectInfo{}, f.mapErr(name, err)
+	}
+	st, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, ObjectInfo{}, err
+	}
+	return file, fileInfo(name, st), nil
+}
+
+func (f *Filesystem) Stat(ctx context.Context, name string) (ObjectInfo, error) {
+	p, err := f.path(name)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	st, err := os.Stat(p)
+	if err != nil {
+		return ObjectInfo{}, f.mapErr(name, err)
+	}
+	return fileInfo(name, st), nil
+}
+
+func (f *Filesystem) Delete(ctx context.Context, name string) error {
+	p, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	return f.mapErr(name, os.Remove(p))
+}
+
+func (f *Filesystem) Presign(ctx context.Context, name string, expiry time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+func (f *Filesystem) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var out []ObjectInfo
+	err := filepath.WalkDir(f.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(f.Root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		st, err := d.Info()
+		if err != nil {
+			return err
+		}
+		out = append(out, fileInfo(name, st))
+		return nil
+	})
+	return out, err
+}
+
+func (f *Filesystem) mapErr(name string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return notFound(name)
+	}
+	return err
+}
+
+func fileInfo(name string, st fs.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Name:         name,
+		Size:         st.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(name)),
+		LastModified: st.ModTime(),
+	}
+}
@@ -0,0 +1,99 @@
+// internal/storage/memory.go
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory keeps objects in process memory; contents are lost on restart
+type Memory struct {
+	mu      sync.RWMutex
+	objects map[string]memObject
+}
+
+type memObject struct {
+	data []byte
+	info ObjectInfo
+}
+
+// memReader adapts bytes.Reader to Object
+type memReader struct {
+	*bytes.Reader
+}
+
+func (memReader) Close() error { return nil }
+
+func NewMemory() *Memory {
+	return &Memory{objects: map[string]memObject{}}
+}
+
+func (m *Memory) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return fmt.Errorf("short write for %s: got %d of %d bytes", name, len(data), size)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[name] = memObject{
+		data: data,
+		info: ObjectInfo{Name: name, Size: int64(len(data)), ContentType: contentType, LastModified: time.Now()},
+	}
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, name string) (Object, ObjectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	obj, ok := m.objects[name]
+	if !ok {
+		return nil, ObjectInfo{}, notFound(name)
+	}
+	return memReader{bytes.NewReader(obj.data)}, obj.info, nil
+}
+
+func (m *Memory) Stat(ctx context.Context, name string) (ObjectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	obj, ok := m.objects[name]
+	if !ok {
+		return ObjectInfo{}, notFound(name)
+	}
+	return obj.info, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.objects[name]; !ok {
+		return notFound(name)
+	}
+	delete(m.objects, name)
+	return nil
+}
+
+func (m *Memory) Presign(ctx context.Context, name string, expiry time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+func (m *Memory) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []ObjectInfo
+	for name, obj := range m.objects {
+		if strings.HasPrefix(name, prefix) {
+			out = append(out, obj.info)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
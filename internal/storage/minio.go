@@ -3,9 +3,8 @@ package storage
 
 import (
 	"context"
-
+	"fmt"
 	"io"
-	"log"
 	"net/url"
 	"time"
 
@@ -20,26 +19,25 @@ type MinioClient struct {
 	UseSSL     bool
 }
 
-func NewMinio(endpoint, accessKey, secretKey, bucket string, useSSL bool) *MinioClient {
+// NewMinio connects to MinIO and makes sure the bucket exists
+func NewMinio(endpoint, accessKey, secretKey, bucket string, useSSL bool) (*MinioClient, error) {
 	mc, err := minio.New(endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
 		Secure: useSSL,
 	})
 	if err != nil {
-		log.Fatalf("failed to init minio client: %v", err)
+		return nil, fmt.Errorf("init minio client: %w", err)
 	}
 
 	// Ensure bucket
 	ctx := context.Background()
 	exists, err := mc.BucketExists(ctx, bucket)
 	if err != nil {
-		// try to create
-		if err := mc.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
-			log.Fatalf("failed to make bucket %s: %v", bucket, err)
-		}
-	} else if !exists {
+		return nil, fmt.Errorf("check bucket %s: %w", bucket, err)
+	}
+	if !exists {
 		if err := mc.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
-			log.Fatalf("failed to make bucket %s: %v", bucket, err)
+			return nil, fmt.Errorf("make bucket %s: %w", bucket, err)
 		}
 	}
 
@@ -48,25 +46,79 @@ func NewMinio(endpoint, accessKey, secretKey, bucket string, useSSL bool) *Minio
 		BucketName: bucket,
 		Endpoint:   endpoint,
 		UseSSL:     useSSL,
-	}
+	}, nil
 }
 
-// UploadStream puts an io.Reader to MinIO and returns object name
-func (m *MinioClient) UploadStream(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) (string, error) {
+// Put streams reader into the bucket; size may be -1 when unknown
+func (m *MinioClient) Put(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
 	_, err := m.Client.PutObject(ctx, m.BucketName, objectName, reader, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get returns a seekable reader for an object together with its metadata
+func (m *MinioClient) Get(ctx context.Context, objectName string) (Object, ObjectInfo, error) {
+	obj, err := m.Client.GetObject(ctx, m.BucketName, objectName, minio.GetObjectOptions{})
 	if err != nil {
-		return "", err
+		return nil, ObjectInfo{}, m.mapErr(objectName, err)
+	}
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, ObjectInfo{}, m.mapErr(objectName, err)
 	}
-	return objectName, nil
+	return obj, objectInfo(info), nil
 }
 
-// PresignedURL returns a presigned GET URL for an object
-// PresignedURL returns a presigned GET URL for an object
-func (m *MinioClient) PresignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
-	reqParams := url.Values{} // ✅ correct type
-	u, err := m.Client.PresignedGetObject(ctx, m.BucketName, objectName, expiry, reqParams)
+// Stat returns object metadata without reading its content
+func (m *MinioClient) Stat(ctx context.Context, objectName string) (ObjectInfo, error) {
+	info, err := m.Client.StatObject(ctx, m.BucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, m.mapErr(objectName, err)
+	}
+	return objectInfo(info), nil
+}
+
+func (m *MinioClient) Delete(ctx context.Context, objectName string) error {
+	if _, err := m.Stat(ctx, objectName); err != nil {
+		return err
+	}
+	return m.Client.RemoveObject(ctx, m.BucketName, objectName, minio.RemoveObjectOptions{})
+}
+
+// Presign returns a presigned GET URL for an object
+func (m *MinioClient) Presign(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
+	u, err := m.Client.PresignedGetObject(ctx, m.BucketName, objectName, expiry, url.Values{})
 	if err != nil {
 		return "", err
 	}
 	return u.String(), nil
 }
+
+func (m *MinioClient) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var out []ObjectInfo
+	for info := range m.Client.ListObjects(ctx, m.BucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		out = append(out, objectInfo(info))
+	}
+	return out, nil
+}
+
+func (m *MinioClient) mapErr(objectName string, err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return notFound(objectName)
+	}
+	return err
+}
+
+func objectInfo(info minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Name:         info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+		ETag:         info.ETag,
+	}
+}
@@ -0,0 +1,161 @@
+// internal/storage/multipart.go
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Part is one uploaded chunk of a multipart upload
+type Part struct {
+	Number int
+	ETag   string
+	Size   int64
+}
+
+// Multipart assembles an object from parts uploaded independently and in any order
+type Multipart interface {
+	NewMultipartUpload(ctx context.Context, name, contentType string) (uploadID string, err error)
+	PutPart(ctx context.Context, name, uploadID string, number int, r io.Reader, size int64) (Part, error)
+	CompleteMultipartUpload(ctx context.Context, name, uploadID string, parts []Part) error
+	AbortMultipartUpload(ctx context.Context, name, uploadID string) error
+}
+
+// MultipartFor returns the backend's native multipart support, or one that keeps
+// parts as ordinary objects and concatenates them on completion
+func MultipartFor(b Backend) Multipart {
+	if m, ok := b.(Multipart); ok {
+		return m
+	}
+	return stagedMultipart{b}
+}
+
+// ---------------- MinIO ----------------
+
+func (m *MinioClient) NewMultipartUpload(ctx context.Context, objectName, contentType string) (string, error) {
+	core := minio.Core{Client: m.Client}
+	return core.NewMultipartUpload(ctx, m.BucketName, objectName, minio.PutObjectOptions{ContentType: contentType})
+}
+
+func (m *MinioClient) PutPart(ctx context.Context, objectName, uploadID string, number int, r io.Reader, size int64) (Part, error) {
+	core := minio.Core{Client: m.Client}
+	p, err := core.PutObjectPart(ctx, m.BucketName, objectName, uploadID, number, r, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return Part{}, err
+	}
+	return Part{Number: p.PartNumber, ETag: p.ETag, Size: p.Size}, nil
+}
+
+func (m *MinioClient) CompleteMultipartUpload(ctx context.Context, objectName, uploadID string, parts []Part) error {
+	core := minio.Core{Client: m.Client}
+	complete := make([]minio.CompletePart, len(parts))
+	for i, p := range parts {
+		complete[i] = minio.CompletePart{PartNumber: p.Number, ETag: p.ETag}
+	}
+	sort.Slice(complete, func(i, j int) bool { return complete[i].PartNumber < complete[j].PartNumber })
+	_, err := core.CompleteMultipartUpload(ctx, m.BucketName, objectName, uploadID, complete, minio.PutObjectOptions{})
+	return err
+}
+
+func (m *MinioClient) AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error {
+	core := minio.Core{Client: m.Client}
+	return core.AbortMultipartUpload(ctx, m.BucketName, objectName, uploadID)
+}
+
+// ---------------- Staged ----------------
+
+// stagedMultipart keeps parts under .multipart/<uploadID>/ in the backend itself
+type stagedMultipart struct {
+	b Backend
+}
+
+const stagingPrefix = ".multipart/"
+
+func stagedPartName(uploadID string, number int) string {
+	return fmt.Sprintf("%s%s/part-%05d", stagingPrefix, uploadID, number)
+}
+
+func stagedTypeName(uploadID string) string {
+	return stagingPrefix + uploadID + "/content-type"
+}
+
+func (s stagedMultipart) NewMultipartUpload(ctx context.Context, name, contentType string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	uploadID := hex.EncodeToString(buf)
+	if err := s.b.Put(ctx, stagedTypeName(uploadID), strings.NewReader(contentType), int64(len(contentType)), "text/plain"); err != nil {
+		return "", err
+	}
+	return uploadID, nil
+}
+
+func (s stagedMultipart) PutPart(ctx context.Context, name, uploadID string, number int, r io.Reader, size int64) (Part, error) {
+	if _, err := s.b.Stat(ctx, stagedTypeName(uploadID)); err != nil {
+		return Part{}, err
+	}
+	d := NewDigestReader(r)
+	if err := s.b.Put(ctx, stagedPartName(uploadID, number), d, size, "application/octet-stream"); err != nil {
+		return Part{}, err
+	}
+	return Part{Number: number, ETag: d.Digests().MD5, Size: d.BytesRead()}, nil
+}
+
+func (s stagedMultipart) CompleteMultipartUpload(ctx context.Context, name, uploadID string, parts []Part) error {
+	contentType, err := s.readContentType(ctx, uploadID)
+	if err != nil {
+		return err
+	}
+
+	sorted := append([]Part(nil), parts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Number < sorted[j].Number })
+
+	var total int64
+	readers := make([]io.Reader, 0, len(sorted))
+	for _, p := range sorted {
+		obj, info, err := s.b.Get(ctx, stagedPartName(uploadID, p.Number))
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		readers = append(readers, obj)
+		total += info.Size
+	}
+
+	if err := s.b.Put(ctx, name, io.MultiReader(readers...), total, contentType); err != nil {
+		return err
+	}
+	return s.AbortMultipartUpload(ctx, name, uploadID)
+}
+
+// AbortMultipartUpload removes all staged parts of the upload
+func (s stagedMultipart) AbortMultipartUpload(ctx context.Context, name, uploadID string) error {
+	staged, err := s.b.List(ctx, stagingPrefix+uploadID+"/")
+	if err != nil {
+		return err
+	}
+	for _, o := range staged {
+		if err := s.b.Delete(ctx, o.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s stagedMultipart) readContentType(ctx context.Context, uploadID string) (string, error) {
+	obj, _, err := s.b.Get(ctx, stagedTypeName(uploadID))
+	if err != nil {
+		return "", err
+	}
+	defer obj.Close()
+	b, err := io.ReadAll(obj)
+	return string(b), err
+}
@@ -0,0 +1,74 @@
+// internal/workflow/workflow.go
+package workflow
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Item states
+const (
+	StateDraft            = "DRAFT"
+	StateSubmitted        = "SUBMITTED"
+	StateInReview         = "IN_REVIEW"
+	StateChangesRequested = "CHANGES_REQUESTED"
+	StatePublished        = "PUBLISHED"
+	StateRejected         = "REJECTED"
+	StateWithdrawn        = "WITHDRAWN"
+)
+
+var ErrIllegalTransition = errors.New("illegal status transition")
+
+// transitions lists every allowed move; IN_REVIEW -> IN_REVIEW advances to the next reviewer step
+var transitions = map[string][]string{
+	StateDraft:            {StateSubmitted, StateWithdrawn},
+	StateSubmitted:        {StateInReview, StateRejected, StateWithdrawn},
+	StateInReview:         {StateInReview, StatePublished, StateChangesRequested, StateRejected},
+	StateChangesRequested: {StateSubmitted, StateWithdrawn},
+	StatePublished:        {StateWithdrawn},
+	StateRejected:         {},
+	StateWithdrawn:        {},
+}
+
+// TransitionError describes a rejected status change
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move item from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrIllegalTransition
+}
+
+// IsState reports whether s is a known workflow state
+func IsState(s string) bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransition reports whether an item in state from may move to state to
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition returns a *TransitionError when the move is not allowed
+func Transition(from, to string) error {
+	if !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// Editable reports whether files and metadata may still be changed by the submitter
+func Editable(state string) bool {
+	return state == StateDraft || state == StateChangesRequested
+}
@@ -0,0 +1,68 @@
+// internal/workflow/workflow_test.go
+package workflow
+
+import (
+	"errors"
+	"testing"
+)
+
+var states = []string{StateDraft, StateSubmitted, StateInReview, StateChangesRequested, StatePublished, StateRejected, StateWithdrawn}
+
+func TestTransition(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{StateDraft, StateSubmitted}:            true,
+		{StateDraft, StateWithdrawn}:            true,
+		{StateSubmitted, StateInReview}:         true,
+		{StateSubmitted, StateRejected}:         true,
+		{StateSubmitted, StateWithdrawn}:        true,
+		{StateInReview, StateInReview}:          true, // next reviewer step
+		{StateInReview, StatePublished}:         true,
+		{StateInReview, StateChangesRequested}:  true,
+		{StateInReview, StateRejected}:          true,
+		{StateChangesRequested, StateSubmitted}: true,
+		{StateChangesRequested, StateWithdrawn}: true,
+		{StatePublished, StateWithdrawn}:        true,
+	}
+	// Every pair of states, so a move added to the map without a test fails here
+	for _, from := range append(states, "UNKNOWN") {
+		for _, to := range append(states, "UNKNOWN") {
+			want := allowed[[2]string{from, to}]
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+			}
+			err := Transition(from, to)
+			if want {
+				if err != nil {
+					t.Errorf("Transition(%s, %s) = %v", from, to, err)
+				}
+				continue
+			}
+			var te *TransitionError
+			if !errors.As(err, &te) || te.From != from || te.To != to || !errors.Is(err, ErrIllegalTransition) {
+				t.Errorf("Transition(%s, %s) = %v, want a TransitionError", from, to, err)
+			}
+		}
+	}
+}
+
+func TestIsState(t *testing.T) {
+	for _, s := range states {
+		if !IsState(s) {
+			t.Errorf("IsState(%s) = false", s)
+		}
+	}
+	for _, s := range []string{"", "draft", "ARCHIVED"} {
+		if IsState(s) {
+			t.Errorf("IsState(%q) = true", s)
+		}
+	}
+}
+
+func TestEditable(t *testing.T) {
+	for _, s := range states {
+		want := s == StateDraft || s == StateChangesRequested
+		if got := Editable(s); got != want {
+			t.Errorf("Editable(%s) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"summarizer/internal"
@@ -15,10 +16,15 @@ func main() {
 	pdfPath := flag.String("input", "", "Path to PDF judgment")
 	query := flag.String("query", "", "Question or 'summary' to generate summary")
 	configPath := flag.String("config", "config.yaml", "Path to config")
+	collection := flag.String("collection", "", "Collection the judgment is stored under; with --corpus, the collection to search")
+	corpus := flag.Bool("corpus", false, "Answer the question across all published repository items instead of one PDF")
+	syncRepo := flag.Bool("sync", false, "Embed the published items of the repository before answering")
+	topK := flag.Int("top-k", 40, "Chunks retrieved across the repository with --corpus")
+	maxItems := flag.Int("max-items", 8, "Items the --corpus answer draws on")
 	flag.Parse()
 
-	if *pdfPath == "" || *query == "" {
-		log.Fatal("Usage: --input file.pdf --query 'summary' or any question")
+	if !*syncRepo && (*query == "" || (*pdfPath == "" && !*corpus)) {
+		log.Fatal("Usage: --input file.pdf --query 'summary' or any question | --corpus --query 'question' | --sync")
 	}
 
 	cfg, err := internal.LoadConfig(*configPath)
@@ -26,6 +32,25 @@ func main() {
 		log.Fatalf("Failed loading config: %v", err)
 	}
 
+	if *syncRepo {
+		stats, err := internal.SyncRepository(context.Background(), cfg)
+		if err != nil {
+			log.Fatalf("Repository sync failed: %v", err)
+		}
+		log.Printf("Repository sync: %d items embedded, %d unchanged, %d removed", stats.Embedded, stats.Unchanged, stats.Removed)
+		if *query == "" {
+			return
+		}
+	}
+
+	if *corpus {
+		answerAcrossRepository(cfg, *query, *collection, *topK, *maxItems)
+		return
+	}
+	if *pdfPath == "" {
+		log.Fatal("Usage: --input file.pdf --query 'summary' or any question")
+	}
+
 	text, err := internal.ExtractTextFromPDF(*pdfPath)
 	if err != nil {
 		log.Fatalf("PDF extraction failed: %v", err)
@@ -42,7 +67,7 @@ func main() {
 			log.Fatalf("Embedding generation failed: %v", err)
 		}
 		emb64 := internal.Float32ToFloat64(emb32)
-		err = internal.StoreChunkEmbedding(cfg.Database.DSN, cfg.Database.Table, filename, i, chunk, emb64)
+		err = internal.StoreChunkEmbedding(cfg.Database.DSN, cfg.Database.Table, *collection, filename, i, chunk, emb64)
 		if err != nil {
 			log.Fatalf("DB insert failed: %v", err)
 		}
@@ -65,13 +90,12 @@ func main() {
 	}
 
 	// Retrieve relevant chunks
-	topChunks, err := internal.SearchRelevantChunks(
-		cfg.Database.DSN,
-		cfg.Database.Table,
-		filename,
-		queryEmb64,
-		8, // Get more chunks for better context
-	)
+	topChunks, err := internal.SearchRelevantChunks(cfg.Database.DSN, cfg.Database.Table, internal.ChunkQuery{
+		Embedding: queryEmb64,
+		TopK:      8, // Get more chunks for better context
+		Filename:  filename,
+		EfSearch:  cfg.Database.EfSearch,
+	})
 	if err != nil {
 		log.Fatalf("Retrieval failed: %v", err)
 	}
@@ -92,3 +116,49 @@ func main() {
 	fmt.Println("=== COMPREHENSIVE LEGAL ANALYSIS ===")
 	fmt.Println(strings.TrimSpace(answer))
 }
+
+// answerAcrossRepository retrieves the chunks closest to the question from every
+// embedded repository item, groups them by item and answers with citations
+func answerAcrossRepository(cfg *internal.Config, query, collection string, topK, maxItems int) {
+	queryEmb32, err := internal.GenerateEmbedding(cfg.Ollama.BaseURL, cfg.EmbeddingModel, query)
+	if err != nil {
+		log.Fatalf("Query embedding failed: %v", err)
+	}
+
+	chunks, err := internal.SearchRelevantChunks(cfg.Database.DSN, cfg.Database.Table, internal.ChunkQuery{
+		Embedding:  internal.Float32ToFloat64(queryEmb32),
+		TopK:       topK,
+		Collection: collection,
+		ItemsOnly:  true,
+		EfSearch:   cfg.Database.EfSearch,
+	})
+	if err != nil {
+		log.Fatalf("Retrieval failed: %v", err)
+	}
+	groups := internal.GroupByItem(chunks, maxItems)
+	if len(groups) == 0 {
+		log.Fatal("No repository items have been embedded yet; run with --sync first")
+	}
+
+	summarizer, err := internal.NewMultiAgentLegalSummarizer(cfg.Ollama.BaseURL, cfg.Model)
+	if err != nil {
+		log.Fatalf("Failed to create summarizer: %v", err)
+	}
+	answer, err := summarizer.AnswerAcrossItems(context.Background(), groups, query)
+	if err != nil {
+		log.Fatalf("Answering failed: %v", err)
+	}
+
+	fmt.Println("=== ANSWER ACROSS THE REPOSITORY ===")
+	fmt.Println(strings.TrimSpace(answer))
+	fmt.Println()
+	fmt.Println("=== SOURCES ===")
+	for _, g := range groups {
+		positions := make([]string, len(g.Chunks))
+		for i, c := range g.Chunks {
+			positions[i] = strconv.Itoa(c.ChunkID)
+		}
+		fmt.Printf("[item %d] %s (collection %s) chunks %s, best score %.3f\n",
+			g.ItemID, g.Title, g.Collection, strings.Join(positions, ", "), g.Score)
+	}
+}
@@ -1,15 +1,26 @@
+-- Requires the pgvector extension (https://github.com/pgvector/pgvector).
+-- Databases created from an earlier version of this script are upgraded by
+-- the scripts in migrations/, in order.
+CREATE EXTENSION IF NOT EXISTS vector;
+
 CREATE TABLE embeddings (
     id SERIAL PRIMARY KEY,
+    collection TEXT NOT NULL DEFAULT '',
+    item_id INT NOT NULL DEFAULT 0, -- repository item the chunk comes from, 0 for standalone files
+    title TEXT NOT NULL DEFAULT '',
     filename TEXT NOT NULL,
     chunk_id INT NOT NULL,
     text_chunk TEXT NOT NULL,
-    embedding FLOAT8[] NOT NULL,
+    embedding vector(768) NOT NULL, -- dimension of embedding_model (768 for nomic-embed-text)
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 CREATE INDEX idx_filename_chunk ON embeddings(filename, chunk_id);
+CREATE INDEX idx_embeddings_collection ON embeddings(collection);
+CREATE INDEX idx_embeddings_item ON embeddings(item_id);
 
-
+-- Approximate nearest neighbour search by cosine distance across all files
+CREATE INDEX idx_embeddings_hnsw ON embeddings USING hnsw (embedding vector_cosine_ops);
 
 ALTER TABLE embeddings
-ADD CONSTRAINT unique_file_chunk UNIQUE (filename, chunk_id);
+ADD CONSTRAINT unique_item_file_chunk UNIQUE (item_id, filename, chunk_id);
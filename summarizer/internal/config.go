@@ -14,13 +14,19 @@ type Config struct {
 	MaxChunkTokens int    `yaml:"max_chunk_tokens"`
 
 	Database struct {
-		DSN   string `yaml:"dsn"`
-		Table string `yaml:"table"`
+		DSN      string `yaml:"dsn"`
+		Table    string `yaml:"table"`
+		EfSearch int    `yaml:"ef_search"` // HNSW candidates per query; higher is slower but more exact
 	} `yaml:"database"`
 
 	Ollama struct {
 		BaseURL string `yaml:"base_url"`
 	} `yaml:"ollama"`
+
+	// Repository is the mSpace database whose published items --sync embeds
+	Repository struct {
+		DSN string `yaml:"dsn"`
+	} `yaml:"repository"`
 }
 
 // LoadConfig loads YAML config from file path
@@ -42,6 +48,9 @@ func LoadConfig(path string) (*Config, error) {
 	if cfg.Database.DSN == "" || cfg.Database.Table == "" {
 		return nil, fmt.Errorf("invalid config: database.dsn and database.table must be set")
 	}
+	if cfg.Database.EfSearch <= 0 {
+		cfg.Database.EfSearch = 100
+	}
 	if cfg.Ollama.BaseURL == "" {
 		return nil, fmt.Errorf("invalid config: ollama.base_url must be set")
 	}
@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PublishedItem is a repository item anyone may read, with the text of its files
+type PublishedItem struct {
+	ID           int
+	CollectionID int
+	Title        string
+	Text         string
+}
+
+// ItemEvidence is the retrieved chunks of one item
+type ItemEvidence struct {
+	ItemID     int
+	Title      string
+	Collection string
+	Score      float64        // score of the item's best chunk
+	Chunks     []SearchResult // best first
+}
+
+// SyncStats counts what SyncRepository did
+type SyncStats struct {
+	Embedded  int // items (re-)embedded
+	Unchanged int // items whose chunks were already current
+	Removed   int // items no longer published
+}
+
+// EachPublishedItem calls fn for every published, publicly visible item of the
+// repository database, in id order
+func EachPublishedItem(ctx context.Context, dsn string, fn func(PublishedItem) error) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	rows, err := conn.Query(ctx, `
+	SELECT id, collection_id, title, full_text
+	FROM items
+	WHERE deleted_at IS NULL AND status = 'PUBLISHED'
+	  AND (visibility = 'PUBLIC' OR (embargo_until IS NOT NULL AND embargo_until <= now()))
+	ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item PublishedItem
+		var title, text *string
+		if err := rows.Scan(&item.ID, &item.CollectionID, &title, &text); err != nil {
+			return err
+		}
+		if title != nil {
+			item.Title = *title
+		}
+		if text != nil {
+			item.Text = *text
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SyncRepository embeds the published items of the repository so they can be searched
+// across, skipping items whose chunks are unchanged and dropping chunks of items that
+// are no longer published
+func SyncRepository(ctx context.Context, cfg *Config) (SyncStats, error) {
+	var stats SyncStats
+	if cfg.Repository.DSN == "" {
+		return stats, fmt.Errorf("repository.dsn must be set to sync the repository")
+	}
+	conn, err := pgx.Connect(ctx, cfg.Database.DSN)
+	if err != nil {
+		return stats, err
+	}
+	defer conn.Close(ctx)
+
+	published := []int32{} // never NULL, or ANY($1) would match nothing and keep stale chunks
+	err = EachPublishedItem(ctx, cfg.Repository.DSN, func(item PublishedItem) error {
+		published = append(published, int32(item.ID))
+		chunks := ChunkText(item.Text, cfg.MaxChunkTokens)
+		current, err := itemChunksCurrent(ctx, conn, cfg.Database.Table, item, chunks)
+		if err != nil {
+			return err
+		}
+		if current {
+			stats.Unchanged++
+			return nil
+		}
+
+		embeddings := make([][]float64, len(chunks))
+		for i, chunk := range chunks {
+			emb32, err := GenerateEmbedding(cfg.Ollama.BaseURL, cfg.EmbeddingModel, chunk)
+			if err != nil {
+				return fmt.Errorf("embed item %d chunk %d: %w", item.ID, i, err)
+			}
+			embeddings[i] = Float32ToFloat64(emb32)
+		}
+		if err := ReplaceItemChunks(ctx, conn, cfg.Database.Table, item, chunks, embeddings); err != nil {
+			return fmt.Errorf("store item %d: %w", item.ID, err)
+		}
+		log.Printf("Embedded item %d (%d chunks)", item.ID, len(chunks))
+		stats.Embedded++
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	tag, err := conn.Exec(ctx, fmt.Sprintf(`
+	DELETE FROM %s WHERE item_id > 0 AND NOT (item_id = ANY($1))`, cfg.Database.Table), published)
+	if err != nil {
+		return stats, err
+	}
+	stats.Removed = int(tag.RowsAffected())
+	return stats, nil
+}
+
+// itemChunksCurrent reports whether the stored chunks of the item match chunks,
+// title and collection, so it need not be embedded again
+func itemChunksCurrent(ctx context.Context, conn *pgx.Conn, table string, item PublishedItem, chunks []string) (bool, error) {
+	rows, err := conn.Query(ctx, fmt.Sprintf(`
+	SELECT title, collection, text_chunk FROM %s WHERE item_id = $1 ORDER BY chunk_id`, table), item.ID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	i := 0
+	current := true
+	for rows.Next() {
+		var title, collection, text string
+		if err := rows.Scan(&title, &collection, &text); err != nil {
+			return false, err
+		}
+		if i >= len(chunks) || text != chunks[i] || title != item.Title || collection != strconv.Itoa(item.CollectionID) {
+			current = false
+		}
+		i++
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	return current && i == len(chunks), nil
+}
+
+// GroupByItem gathers chunks of repository items under their item, keeping the
+// order of each item's best chunk, and returns at most maxItems items
+func GroupByItem(results []SearchResult, maxItems int) []ItemEvidence {
+	var groups []ItemEvidence
+	index := map[int]int{}
+	for _, r := range results {
+		if r.ItemID == 0 {
+			continue
+		}
+		i, ok := index[r.ItemID]
+		if !ok {
+			if len(groups) == maxItems {
+				continue
+			}
+			i = len(groups)
+			index[r.ItemID] = i
+			groups = append(groups, ItemEvidence{ItemID: r.ItemID, Title: r.Title, Collection: r.Collection, Score: r.Score})
+		}
+		groups[i].Chunks = append(groups[i].Chunks, r)
+	}
+	return groups
+}
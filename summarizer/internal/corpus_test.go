@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupByItem(t *testing.T) {
+	results := []SearchResult{
+		{ItemID: 7, Title: "State v. Rao", Collection: "1", ChunkID: 2, Score: 0.9},
+		{ItemID: 0, Filename: "upload.pdf", ChunkID: 1, Score: 0.85}, // standalone file
+		{ItemID: 3, Title: "Shah v. Union", Collection: "2", ChunkID: 5, Score: 0.8},
+		{ItemID: 7, Title: "State v. Rao", Collection: "1", ChunkID: 4, Score: 0.7},
+		{ItemID: 9, Title: "Third item", Collection: "1", ChunkID: 1, Score: 0.6},
+		{ItemID: 3, Title: "Shah v. Union", Collection: "2", ChunkID: 1, Score: 0.5},
+	}
+	groups := GroupByItem(results, 2)
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	if g := groups[0]; g.ItemID != 7 || g.Title != "State v. Rao" || g.Collection != "1" || g.Score != 0.9 {
+		t.Errorf("first group %+v", g)
+	}
+	if g := groups[1]; g.ItemID != 3 || g.Score != 0.8 {
+		t.Errorf("second group %+v", g)
+	}
+	for i, want := range [][]int{{2, 4}, {5, 1}} {
+		var got []int
+		for _, c := range groups[i].Chunks {
+			got = append(got, c.ChunkID)
+		}
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("group %d chunks %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestBuildCorpusPrompt(t *testing.T) {
+	groups := GroupByItem([]SearchResult{
+		{ItemID: 12, Title: "State v. Rao", ChunkID: 3, Text: "Bail was granted."},
+		{ItemID: 4, Title: "Shah v. Union", ChunkID: 1, Text: "Compensation was enhanced."},
+		{ItemID: 12, Title: "State v. Rao", ChunkID: 8, Text: "The accused was acquitted."},
+	}, 5)
+	prompt := BuildCorpusPrompt(groups, "When is bail granted?")
+
+	for _, want := range []string{
+		"=== Item 12: State v. Rao ===\n[item 12, chunk 3]\nBail was granted.\n---\n[item 12, chunk 8]\nThe accused was acquitted.\n---\n",
+		"=== Item 4: Shah v. Union ===\n[item 4, chunk 1]\nCompensation was enhanced.\n",
+		"\nQuestion: When is bail granted?\nAnswer:",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Index(prompt, "=== Item 12") > strings.Index(prompt, "=== Item 4") {
+		t.Errorf("items should keep their ranking order")
+	}
+}
+
+func TestBuildCorpusPromptTruncates(t *testing.T) {
+	long := strings.Repeat("word ", maxCorpusPromptLength/10)
+	var results []SearchResult
+	for id := 1; id <= 4; id++ {
+		results = append(results, SearchResult{ItemID: id, Title: "Item", ChunkID: 1, Text: long})
+	}
+	prompt := BuildCorpusPrompt(GroupByItem(results, 10), "question")
+
+	if !strings.Contains(prompt, "[item 1, chunk 1]") {
+		t.Fatalf("the best item should always fit")
+	}
+	// Each item takes half the budget, so after the instructions only the best
+	// one fits and the rest are left out whole
+	if strings.Contains(prompt, "[item 2, chunk 1]") || strings.Contains(prompt, "[item 3, chunk 1]") {
+		t.Errorf("items beyond the limit were kept")
+	}
+	body := prompt[:strings.Index(prompt, "\nQuestion: ")]
+	if len(body) > maxCorpusPromptLength {
+		t.Errorf("evidence is %d bytes, limit %d", len(body), maxCorpusPromptLength)
+	}
+	if !strings.HasSuffix(prompt, "\nQuestion: question\nAnswer:") {
+		t.Errorf("question missing after truncation")
+	}
+}
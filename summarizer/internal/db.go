@@ -3,11 +3,12 @@ package internal
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/jackc/pgx/v5"
 )
 
-func StoreChunkEmbedding(dsn, table, filename string, chunkID int, chunkText string, embedding []float64) error {
+func StoreChunkEmbedding(dsn, table, collection, filename string, chunkID int, chunkText string, embedding []float64) error {
 	conn, err := pgx.Connect(context.Background(), dsn)
 	if err != nil {
 		return err
@@ -15,11 +16,51 @@ func StoreChunkEmbedding(dsn, table, filename string, chunkID int, chunkText str
 	defer conn.Close(context.Background())
 
 	sql := fmt.Sprintf(`
-	INSERT INTO %s (filename, chunk_id, text_chunk, embedding)
-	VALUES ($1, $2, $3, $4)
-	ON CONFLICT (filename, chunk_id)
-	DO UPDATE SET text_chunk = EXCLUDED.text_chunk, embedding = EXCLUDED.embedding`, table)
+	INSERT INTO %s (collection, filename, chunk_id, text_chunk, embedding)
+	VALUES ($1, $2, $3, $4, $5::vector)
+	ON CONFLICT (item_id, filename, chunk_id)
+	DO UPDATE SET collection = EXCLUDED.collection, text_chunk = EXCLUDED.text_chunk, embedding = EXCLUDED.embedding`, table)
 
-	_, err = conn.Exec(context.Background(), sql, filename, chunkID, chunkText, embedding)
+	_, err = conn.Exec(context.Background(), sql, collection, filename, chunkID, chunkText, VectorLiteral(embedding))
 	return err
 }
+
+// ReplaceItemChunks swaps the stored chunks of a repository item for fresh ones in
+// one transaction; chunk ids are the chunks' positions in the item's text
+func ReplaceItemChunks(ctx context.Context, conn *pgx.Conn, table string, item PublishedItem, chunks []string, embeddings [][]float64) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE item_id = $1", table), item.ID); err != nil {
+		return err
+	}
+	sql := fmt.Sprintf(`
+	INSERT INTO %s (collection, item_id, title, filename, chunk_id, text_chunk, embedding)
+	VALUES ($1, $2, $3, $4, $5, $6, $7::vector)`, table)
+	collection := strconv.Itoa(item.CollectionID)
+	filename := fmt.Sprintf("item-%d", item.ID)
+	for i, chunk := range chunks {
+		_, err := tx.Exec(ctx, sql, collection, item.ID, item.Title, filename, i, chunk, VectorLiteral(embeddings[i]))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// VectorLiteral renders an embedding in pgvector's text format, e.g. [0.1,-0.2,0.3],
+// so it can be passed as a parameter and cast with ::vector
+func VectorLiteral(v []float64) string {
+	b := make([]byte, 0, 2+len(v)*10)
+	b = append(b, '[')
+	for i, x := range v {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendFloat(b, x, 'g', -1, 32)
+	}
+	return string(append(b, ']'))
+}
@@ -0,0 +1,21 @@
+package internal
+
+import "testing"
+
+func TestVectorLiteral(t *testing.T) {
+	cases := []struct {
+		in   []float64
+		want string
+	}{
+		{nil, "[]"},
+		{[]float64{1}, "[1]"},
+		{[]float64{1, -0.5, 0.1, 0}, "[1,-0.5,0.1,0]"},
+		{[]float64{0.123456789}, "[0.12345679]"}, // float32 precision, like the column
+		{[]float64{1e-7, -3e20}, "[1e-07,-3e+20]"},
+	}
+	for _, tc := range cases {
+		if got := VectorLiteral(tc.in); got != tc.want {
+			t.Errorf("VectorLiteral(%v) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
@@ -268,3 +268,13 @@ Answer:`, combinedText.String(), query)
 
 	return m.generateText(ctx, prompt, 2048)
 }
+
+// AnswerAcrossItems answers a question from evidence retrieved across many items,
+// citing items and chunk positions
+func (m *MultiAgentLegalSummarizer) AnswerAcrossItems(ctx context.Context, groups []ItemEvidence, query string) (string, error) {
+	if len(groups) == 0 {
+		return "", fmt.Errorf("no evidence to answer from")
+	}
+	log.Printf("Answering across %d items", len(groups))
+	return m.generateText(ctx, BuildCorpusPrompt(groups, query), 2048)
+}
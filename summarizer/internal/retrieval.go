@@ -12,9 +12,24 @@ import (
 )
 
 type SearchResult struct {
-	ChunkID int
-	Text    string
-	Score   float64
+	Collection string
+	ItemID     int // 0 for standalone files
+	Title      string
+	Filename   string
+	ChunkID    int
+	Text       string
+	Score      float64
+}
+
+// ChunkQuery asks for the TopK chunks closest to Embedding; an empty Filename or
+// Collection matches every file or collection
+type ChunkQuery struct {
+	Embedding  []float64
+	TopK       int
+	Filename   string
+	Collection string
+	ItemsOnly  bool // only chunks of repository items
+	EfSearch   int  // HNSW candidate list size, raised to TopK when smaller
 }
 
 func CosineSimilarity(vec1, vec2 []float64) (float64, error) {
@@ -33,16 +48,54 @@ func CosineSimilarity(vec1, vec2 []float64) (float64, error) {
 	return dot / (math.Sqrt(magA) * math.Sqrt(magB)), nil
 }
 
-func SearchRelevantChunks(dsn, table, filename string, queryEmbedding []float64, topK int) ([]SearchResult, error) {
-	conn, err := pgx.Connect(context.Background(), dsn)
+// SearchRelevantChunks returns the chunks nearest to the query embedding by cosine
+// distance, best first. Postgres answers from the HNSW index, so results are
+// approximate; filters are applied to the index candidates, and a larger EfSearch
+// keeps selective filters from returning fewer than TopK chunks. Chunks of a single
+// file are few, so they are ranked exactly instead.
+func SearchRelevantChunks(dsn, table string, q ChunkQuery) ([]SearchResult, error) {
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
 	if err != nil {
 		log.Printf("Failed to connect to DB: %v", err)
 		return nil, err
 	}
-	defer conn.Close(context.Background())
+	defer conn.Close(ctx)
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	if q.Filename != "" {
+		// The HNSW scan would filter a fixed candidate list and could miss every chunk
+		// of the file on pgvector < 0.8; without index scans Postgres compares them all
+		if _, err := tx.Exec(ctx, "SET LOCAL enable_indexscan = off"); err != nil {
+			log.Printf("Disabling index scans failed: %v", err)
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", max(q.EfSearch, q.TopK))); err != nil {
+		log.Printf("Setting hnsw.ef_search failed: %v", err)
+		return nil, err
+	}
+	// pgvector 0.8+ keeps scanning the index until enough rows pass the filters; older
+	// versions reject the setting, so it is tried in a savepoint
+	if sp, err := tx.Begin(ctx); err == nil {
+		if _, err := sp.Exec(ctx, "SET LOCAL hnsw.iterative_scan = relaxed_order"); err != nil {
+			sp.Rollback(ctx)
+		} else {
+			sp.Commit(ctx)
+		}
+	}
 
-	log.Printf("Querying chunks for file: %s", filename)
-	rows, err := conn.Query(context.Background(), fmt.Sprintf("SELECT chunk_id, text_chunk, embedding FROM %s WHERE filename=$1", table), filename)
+	log.Printf("Querying top %d chunks (file=%q, collection=%q)", q.TopK, q.Filename, q.Collection)
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+	SELECT collection, item_id, title, filename, chunk_id, text_chunk, 1 - (embedding <=> $1::vector) AS score
+	FROM %s
+	WHERE ($2 = '' OR filename = $2) AND ($3 = '' OR collection = $3) AND (NOT $5 OR item_id > 0)
+	ORDER BY embedding <=> $1::vector
+	LIMIT $4`, table), VectorLiteral(q.Embedding), q.Filename, q.Collection, q.TopK, q.ItemsOnly)
 	if err != nil {
 		log.Printf("DB query failed: %v", err)
 		return nil, err
@@ -51,41 +104,31 @@ func SearchRelevantChunks(dsn, table, filename string, queryEmbedding []float64,
 
 	results := []SearchResult{}
 	for rows.Next() {
-		var chunkID int
-		var text string
-		var embedding []float64
-		err := rows.Scan(&chunkID, &text, &embedding)
-		if err != nil {
+		var res SearchResult
+		if err := rows.Scan(&res.Collection, &res.ItemID, &res.Title, &res.Filename, &res.ChunkID, &res.Text, &res.Score); err != nil {
 			log.Printf("Row scan failed, skipping chunk: %v", err)
 			continue
 		}
-		score, err := CosineSimilarity(queryEmbedding, embedding)
-		if err != nil {
-			log.Printf("Cosine similarity failed for chunk %d: %v", chunkID, err)
-			continue
-		}
-		results = append(results, SearchResult{ChunkID: chunkID, Text: text, Score: score})
+		results = append(results, res)
 	}
-
-	if len(results) == 0 {
-		log.Printf("No chunks found for file %s", filename)
-		return nil, nil
+	if err := rows.Err(); err != nil {
+		log.Printf("DB query failed: %v", err)
+		return nil, err
 	}
 
-	sort.Slice(results, func(i, j int) bool {
+	// relaxed_order may return neighbours slightly out of order
+	sort.SliceStable(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
 	})
 
-	log.Printf("Top %d chunks retrieved by similarity:", topK)
-	for i, res := range results {
-		if i >= topK {
-			break
-		}
-		log.Printf("Chunk %d: score=%.4f, text snippet=%q", res.ChunkID, res.Score, snippet(res.Text, 100))
+	if len(results) == 0 {
+		log.Printf("No chunks found (file=%q, collection=%q)", q.Filename, q.Collection)
+		return nil, nil
 	}
 
-	if len(results) > topK {
-		results = results[:topK]
+	log.Printf("Top %d chunks retrieved by similarity:", len(results))
+	for _, res := range results {
+		log.Printf("%s chunk %d: score=%.4f, text snippet=%q", res.Filename, res.ChunkID, res.Score, snippet(res.Text, 100))
 	}
 
 	return results, nil
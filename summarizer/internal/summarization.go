@@ -43,6 +43,37 @@ func BuildContextPrompt(chunks []SearchResult, query string) string {
 	return prompt
 }
 
+// maxCorpusPromptLength bounds the evidence sent with a cross-document question;
+// the weakest items are dropped first
+const maxCorpusPromptLength = 16000
+
+// BuildCorpusPrompt lays out evidence grouped by item, best item first, with every
+// excerpt labelled by the citation the answer must use, e.g. [item 12, chunk 3]
+func BuildCorpusPrompt(groups []ItemEvidence, query string) string {
+	var sb strings.Builder
+	sb.WriteString("You are a legal expert answering a question across several judgments from a repository.\n")
+	sb.WriteString("Use only the excerpts below. Cite every statement with the label of the excerpt it comes from, ")
+	sb.WriteString("e.g. [item 12, chunk 3]. Name the judgments that discuss the question and say briefly how; ")
+	sb.WriteString("if none of them do, say so.\n\n")
+
+	for _, g := range groups {
+		var item strings.Builder
+		fmt.Fprintf(&item, "=== Item %d: %s ===\n", g.ItemID, g.Title)
+		for _, c := range g.Chunks {
+			fmt.Fprintf(&item, "[item %d, chunk %d]\n%s\n---\n", c.ItemID, c.ChunkID, c.Text)
+		}
+		if sb.Len()+item.Len() > maxCorpusPromptLength {
+			log.Printf("Prompt limit reached, leaving out item %d and weaker items", g.ItemID)
+			break
+		}
+		sb.WriteString(item.String())
+	}
+	sb.WriteString("\nQuestion: ")
+	sb.WriteString(query)
+	sb.WriteString("\nAnswer:")
+	return sb.String()
+}
+
 func SummarizeWithLangChain(ctx context.Context, baseURL, model, prompt string) (string, error) {
 	// Create Ollama LLM instance
 	llm, err := ollama.New(
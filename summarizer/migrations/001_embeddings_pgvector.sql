@@ -0,0 +1,19 @@
+-- Moves embeddings from FLOAT8[] to a pgvector column with an HNSW index so chunks
+-- can be searched across all files, and adds the collection used to filter them.
+-- Run once on databases created from the original init_db.sql:
+--   psql "$DSN" -f migrations/001_embeddings_pgvector.sql
+-- The dimension must match embedding_model (768 for nomic-embed-text); rows embedded
+-- with another model fail the conversion and must be deleted and re-embedded first.
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS vector;
+
+ALTER TABLE embeddings ADD COLUMN IF NOT EXISTS collection TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE embeddings
+    ALTER COLUMN embedding TYPE vector(768) USING embedding::vector(768);
+
+CREATE INDEX IF NOT EXISTS idx_embeddings_collection ON embeddings(collection);
+CREATE INDEX IF NOT EXISTS idx_embeddings_hnsw ON embeddings USING hnsw (embedding vector_cosine_ops);
+
+COMMIT;
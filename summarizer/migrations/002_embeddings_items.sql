@@ -0,0 +1,16 @@
+-- Records the repository item each chunk comes from so questions can be answered
+-- across the whole repository with citations to item ids. Chunks of standalone
+-- files (--input) keep item_id 0.
+--   psql "$DSN" -f migrations/002_embeddings_items.sql
+BEGIN;
+
+ALTER TABLE embeddings ADD COLUMN IF NOT EXISTS item_id INT NOT NULL DEFAULT 0;
+ALTER TABLE embeddings ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_embeddings_item ON embeddings(item_id);
+
+-- Items may share file names, so uniqueness now includes the item
+ALTER TABLE embeddings DROP CONSTRAINT IF EXISTS unique_file_chunk;
+ALTER TABLE embeddings ADD CONSTRAINT unique_item_file_chunk UNIQUE (item_id, filename, chunk_id);
+
+COMMIT;